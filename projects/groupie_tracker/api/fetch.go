@@ -3,13 +3,55 @@ package api
 import (
 	"encoding/json"
 	"fmt"
+	"groupie_tracker/metrics"
 	"groupie_tracker/models"
 	"net/http"
+	"strings"
+	"time"
 )
 
 const BaseURL = "https://groupietrackers.herokuapp.com/api"
 
+var (
+	fetchDuration = metrics.NewHistogramVec(
+		"groupie_upstream_fetch_duration_seconds",
+		"Time spent fetching from the upstream API.",
+		nil,
+		"endpoint",
+	)
+	fetchErrors = metrics.NewCounterVec(
+		"groupie_upstream_fetch_errors_total",
+		"Failed fetches from the upstream API.",
+		"endpoint",
+	)
+)
+
+// endpointOf names the upstream resource of a URL for metric labels,
+// e.g. ".../api/locations/3" is "locations"
+func endpointOf(url string) string {
+	path, ok := strings.CutPrefix(url, BaseURL+"/")
+	if !ok {
+		return "other"
+	}
+	endpoint, _, _ := strings.Cut(path, "/")
+	return endpoint
+}
+
+// FetchData decodes the JSON at url into target, recording its latency
+// and failures per upstream endpoint
 func FetchData(url string, target any) error {
+	endpoint := endpointOf(url)
+	start := time.Now()
+	defer fetchDuration.With(endpoint).ObserveSince(start)
+
+	err := fetch(url, target)
+	if err != nil {
+		fetchErrors.With(endpoint).Inc()
+	}
+	return err
+}
+
+func fetch(url string, target any) error {
 	// 1. Make HTTP GET request
 	resp, err := http.Get(url)
 	if err != nil {
@@ -59,3 +101,24 @@ func GetRelations(url string) (models.Relation, error) {
 	err := FetchData(url, &relation)
 	return relation, err
 }
+
+// GetLocationsIndex fetches the locations of every artist in a single request
+func GetLocationsIndex() (models.LocationsIndex, error) {
+	var index models.LocationsIndex
+	err := FetchData(BaseURL+"/locations", &index)
+	return index, err
+}
+
+// GetDatesIndex fetches the concert dates of every artist in a single request
+func GetDatesIndex() (models.DatesIndex, error) {
+	var index models.DatesIndex
+	err := FetchData(BaseURL+"/dates", &index)
+	return index, err
+}
+
+// GetRelationIndex fetches the dates/locations relation of every artist in a single request
+func GetRelationIndex() (models.RelationIndex, error) {
+	var index models.RelationIndex
+	err := FetchData(BaseURL+"/relation", &index)
+	return index, err
+}
@@ -0,0 +1,147 @@
+// Package assets fingerprints the static files so they can be cached
+// for a year: a file's URL changes whenever its content does.
+package assets
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// hashLen is how many hex digits of the content hash go into a URL
+const hashLen = 10
+
+// Precompressed encodings, checked in order of preference
+var encodings = []struct {
+	Name string
+	Ext  string
+}{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+// File is one static file and its precompressed variants
+type File struct {
+	Name        string // slash separated path below the static dir, e.g. "css/style.css"
+	Fingerprint string // Name with the content hash, e.g. "css/style.1a2b3c4d5e.css"
+	Hash        string
+	// Variants maps an encoding ("br", "gzip") to the file holding it
+	Variants map[string]string
+}
+
+// Manifest lists the files that may be served from a static directory.
+// Anything not in it, such as directories and dotfiles, is never served.
+type Manifest struct {
+	Dir           string
+	files         map[string]*File
+	byFingerprint map[string]*File
+}
+
+// Load hashes every file under dir. The manifest is built once, so files
+// changed after startup keep their old fingerprint until a restart.
+func Load(dir string) (*Manifest, error) {
+	m := &Manifest{
+		Dir:           dir,
+		files:         make(map[string]*File),
+		byFingerprint: make(map[string]*File),
+	}
+
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(d.Name(), ".") && p != dir {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || isVariant(p) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		return m.add(filepath.ToSlash(rel), p)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func isVariant(p string) bool {
+	for _, enc := range encodings {
+		if strings.HasSuffix(p, enc.Ext) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Manifest) add(name, p string) error {
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])[:hashLen]
+
+	ext := path.Ext(name)
+	f := &File{
+		Name:        name,
+		Fingerprint: strings.TrimSuffix(name, ext) + "." + hash + ext,
+		Hash:        hash,
+		Variants:    make(map[string]string),
+	}
+	for _, enc := range encodings {
+		if _, err := os.Stat(p + enc.Ext); err == nil {
+			f.Variants[enc.Name] = p + enc.Ext
+		}
+	}
+
+	m.files[f.Name] = f
+	m.byFingerprint[f.Fingerprint] = f
+	return nil
+}
+
+// URL returns the fingerprinted URL of a static file. Unknown names, or a
+// nil manifest, fall back to the plain /static/ URL.
+func (m *Manifest) URL(name string) string {
+	if m != nil {
+		if f, ok := m.files[name]; ok {
+			return "/static/" + f.Fingerprint
+		}
+	}
+	return "/static/" + name
+}
+
+// Lookup resolves a request path below /static/. immutable reports
+// whether it was the fingerprinted URL, which can be cached forever.
+func (m *Manifest) Lookup(name string) (f *File, immutable bool, ok bool) {
+	if f, ok := m.byFingerprint[name]; ok {
+		return f, true, true
+	}
+	f, ok = m.files[name]
+	return f, false, ok
+}
+
+// Path returns where a file lives on disk
+func (m *Manifest) Path(f *File) string {
+	return filepath.Join(m.Dir, filepath.FromSlash(f.Name))
+}
+
+// Encodings lists the supported precompressed encodings in order of preference
+func Encodings() []string {
+	names := make([]string, len(encodings))
+	for i, enc := range encodings {
+		names[i] = enc.Name
+	}
+	return names
+}
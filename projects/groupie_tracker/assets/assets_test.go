@@ -0,0 +1,40 @@
+package assets
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "css"), 0o755)
+	os.WriteFile(filepath.Join(dir, "css", "style.css"), []byte("body{}"), 0o644)
+	os.WriteFile(filepath.Join(dir, "css", "style.css.gz"), []byte("gz"), 0o644)
+	os.WriteFile(filepath.Join(dir, ".secret"), []byte("x"), 0o644)
+
+	m, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Expected no error loading manifest, got: %v", err)
+	}
+
+	url := m.URL("css/style.css")
+	if !strings.HasPrefix(url, "/static/css/style.") || !strings.HasSuffix(url, ".css") || url == "/static/css/style.css" {
+		t.Errorf("Expected a fingerprinted URL, got %q", url)
+	}
+
+	f, immutable, ok := m.Lookup(strings.TrimPrefix(url, "/static/"))
+	if !ok || !immutable || f.Name != "css/style.css" {
+		t.Errorf("Expected the fingerprinted name to resolve immutably, got %+v %v %v", f, immutable, ok)
+	}
+	if _, ok := f.Variants["gzip"]; !ok {
+		t.Error("Expected the .gz sibling to be found")
+	}
+
+	for _, name := range []string{".secret", "css/style.css.gz", "css"} {
+		if _, _, ok := m.Lookup(name); ok {
+			t.Errorf("Expected %q not to be served", name)
+		}
+	}
+}
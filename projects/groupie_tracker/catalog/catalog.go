@@ -0,0 +1,55 @@
+package catalog
+
+import (
+	"groupie_tracker/models"
+	"sync"
+	"time"
+)
+
+// Catalog is the merged view of the four upstream resources.
+// Locations, Dates and Relations are keyed by artist ID.
+type Catalog struct {
+	Artists   []models.Artist          `json:"artists"`
+	Locations map[int]models.Locations `json:"locations"`
+	Dates     map[int]models.Dates     `json:"dates"`
+	Relations map[int]models.Relation  `json:"relations"`
+	FetchedAt time.Time                `json:"fetchedAt"`
+
+	// members is built on first use, see Members
+	members struct {
+		once   sync.Once
+		list   []Member
+		bySlug map[string]int // index in list
+	}
+}
+
+// New merges the upstream index payloads into a Catalog
+func New(artists []models.Artist, locations []models.Locations, dates []models.Dates, relations []models.Relation) *Catalog {
+	c := &Catalog{
+		Artists:   artists,
+		Locations: make(map[int]models.Locations, len(locations)),
+		Dates:     make(map[int]models.Dates, len(dates)),
+		Relations: make(map[int]models.Relation, len(relations)),
+		FetchedAt: time.Now().UTC(),
+	}
+	for _, l := range locations {
+		c.Locations[l.ID] = l
+	}
+	for _, d := range dates {
+		c.Dates[d.ID] = d
+	}
+	for _, r := range relations {
+		c.Relations[r.ID] = r
+	}
+	return c
+}
+
+// Artist returns the artist with the given ID
+func (c *Catalog) Artist(id int) (models.Artist, bool) {
+	for _, artist := range c.Artists {
+		if artist.ID == id {
+			return artist, true
+		}
+	}
+	return models.Artist{}, false
+}
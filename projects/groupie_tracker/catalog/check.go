@@ -0,0 +1,164 @@
+package catalog
+
+import (
+	"fmt"
+	"groupie_tracker/api"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Severities of the issues Check reports
+const (
+	// SeverityError marks data the tracker refuses to load or cannot show
+	SeverityError = "error"
+	// SeverityWarning marks a quirk the tracker works around
+	SeverityWarning = "warning"
+)
+
+// Issue is an inconsistency found by Check
+type Issue struct {
+	Severity string `json:"severity"`
+	Problem
+}
+
+// Report is the result of checking a data source
+type Report struct {
+	CheckedAt time.Time `json:"checkedAt"`
+	Artists   int       `json:"artists"`
+	Issues    []Issue   `json:"issues"`
+}
+
+// Count returns how many issues have the given severity
+func (r Report) Count(severity string) int {
+	n := 0
+	for _, issue := range r.Issues {
+		if issue.Severity == severity {
+			n++
+		}
+	}
+	return n
+}
+
+// Check reads every resource of a data source and reports what does not
+// add up: integrity problems as errors, and upstream quirks such as
+// starred dates, locations missing from the relation, or resource URLs
+// pointing at other hosts as warnings. The error is only for a source
+// that cannot be read at all.
+func Check(src DataSource) (Report, error) {
+	res, err := readAll(src)
+	if err != nil {
+		return Report{}, err
+	}
+
+	report := Report{CheckedAt: time.Now().UTC(), Artists: len(res.artists), Issues: []Issue{}}
+	add := func(severity, resource string, artistID int, format string, args ...any) {
+		report.Issues = append(report.Issues, Issue{
+			Severity: severity,
+			Problem:  Problem{Resource: resource, ArtistID: artistID, Message: fmt.Sprintf(format, args...)},
+		})
+	}
+
+	// 1. Referential integrity, as Load enforces it
+	for _, p := range res.integrity() {
+		report.Issues = append(report.Issues, Issue{Severity: SeverityError, Problem: p})
+	}
+
+	c := res.catalog()
+	for _, artist := range res.artists {
+		id := artist.ID
+
+		// 2. The artist itself
+		if len(artist.Members) == 0 {
+			add(SeverityWarning, "artists", id, "no members listed")
+		}
+		if album, err := ParseDate(artist.FirstAlbum); err != nil {
+			add(SeverityError, "artists", id, "first album date %q cannot be parsed", artist.FirstAlbum)
+		} else if artist.CreationDate > album.Year() {
+			add(SeverityWarning, "artists", id, "created in %d, after its first album in %d", artist.CreationDate, album.Year())
+		}
+		checkURL(add, id, "locations", artist.Locations)
+		checkURL(add, id, "dates", artist.ConcertDates)
+		checkURL(add, id, "relation", artist.Relations)
+
+		// 3. Dates: starred or unparseable
+		dates := make(map[string]bool)
+		for _, d := range c.Dates[id].Dates {
+			if strings.HasPrefix(d, "*") {
+				add(SeverityWarning, "dates", id, "date %q is starred", d)
+			}
+			if _, err := ParseDate(d); err != nil {
+				add(SeverityError, "dates", id, "date %q cannot be parsed", d)
+			}
+			dates[strings.TrimPrefix(d, "*")] = true
+		}
+
+		// 4. The relation must agree with /locations and /dates
+		relation := c.Relations[id].DatesLocations
+		related := make(map[string]bool)
+		for _, location := range sortedKeys(relation) {
+			if !slices.Contains(c.Locations[id].Locations, location) {
+				add(SeverityWarning, "relation", id, "location %q is not in locations", location)
+			}
+			for _, d := range relation[location] {
+				if _, err := ParseDate(d); err != nil {
+					add(SeverityError, "relation", id, "date %q in %s cannot be parsed", d, location)
+				}
+				related[d] = true
+			}
+		}
+		for _, location := range c.Locations[id].Locations {
+			if _, ok := relation[location]; !ok {
+				add(SeverityWarning, "locations", id, "location %q is not in the relation", location)
+			}
+		}
+		for _, d := range sortedSet(dates) {
+			if !related[d] {
+				add(SeverityWarning, "dates", id, "date %q is not in the relation", d)
+			}
+		}
+		for _, d := range sortedSet(related) {
+			if !dates[d] {
+				add(SeverityWarning, "relation", id, "date %q is not in dates", d)
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// checkURL reports an artist's resource URL that points somewhere other
+// than the upstream API entry for that artist. Datasets without URLs
+// are fine.
+func checkURL(add func(string, string, int, string, ...any), id int, resource, link string) {
+	if link == "" {
+		return
+	}
+	want := api.BaseURL + "/" + resource + "/" + strconv.Itoa(id)
+	if link == want {
+		return
+	}
+
+	u, err := url.Parse(link)
+	base, _ := url.Parse(api.BaseURL)
+	switch {
+	case err != nil:
+		add(SeverityWarning, "artists", id, "%s URL %q cannot be parsed", resource, link)
+	case u.Host != base.Host:
+		add(SeverityWarning, "artists", id, "%s URL points at another host: %s", resource, link)
+	default:
+		add(SeverityWarning, "artists", id, "%s URL is %s, expected %s", resource, link, want)
+	}
+}
+
+func sortedSet(m map[string]bool) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,85 @@
+package catalog
+
+import (
+	"strings"
+	"testing"
+
+	"groupie_tracker/api"
+	"groupie_tracker/models"
+)
+
+func TestCheck(t *testing.T) {
+	src := memorySource{
+		artists: []models.Artist{
+			{
+				ID: 1, Name: "Queen", Members: []string{"Freddie Mercury"}, CreationDate: 1970, FirstAlbum: "14-12-1973",
+				Locations:    api.BaseURL + "/locations/1",
+				ConcertDates: "https://mirror.example.com/api/dates/1",
+				Relations:    api.BaseURL + "/relation/2",
+			},
+			{ID: 2, Name: "SOJA", CreationDate: 2001, FirstAlbum: "1999"},
+		},
+		locations: []models.Locations{
+			{ID: 1, Locations: []string{"london-uk", "paris-france"}},
+			{ID: 2, Locations: []string{"osaka-japan"}},
+		},
+		dates: []models.Dates{
+			{ID: 1, Dates: []string{"*01-01-2020", "02-02-2020", "31-02-2020"}},
+			{ID: 2, Dates: []string{"05-05-2021"}},
+		},
+		relations: []models.Relation{
+			{ID: 1, DatesLocations: map[string][]string{"london-uk": {"01-01-2020"}, "paris-france": {"02-02-2020"}}},
+			{ID: 2, DatesLocations: map[string][]string{"osaka_japan": {"05-05-2021", "06-05-2021"}}},
+		},
+	}
+
+	report, err := Check(src)
+	if err != nil {
+		t.Fatalf("Expected no error checking, got: %v", err)
+	}
+
+	want := []string{
+		"warning artists: artist 1: dates URL points at another host: https://mirror.example.com/api/dates/1",
+		"warning artists: artist 1: relation URL is " + api.BaseURL + "/relation/2, expected " + api.BaseURL + "/relation/1",
+		`warning dates: artist 1: date "*01-01-2020" is starred`,
+		`error dates: artist 1: date "31-02-2020" cannot be parsed`,
+		`warning dates: artist 1: date "31-02-2020" is not in the relation`,
+		"warning artists: artist 2: no members listed",
+		`error artists: artist 2: first album date "1999" cannot be parsed`,
+		`warning relation: artist 2: location "osaka_japan" is not in locations`,
+		`warning locations: artist 2: location "osaka-japan" is not in the relation`,
+		`warning relation: artist 2: date "06-05-2021" is not in dates`,
+	}
+	var got []string
+	for _, issue := range report.Issues {
+		got = append(got, issue.Severity+" "+issue.String())
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Expected issues:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+
+	if report.Artists != 2 || report.Count(SeverityError) != 2 || report.Count(SeverityWarning) != 8 {
+		t.Errorf("Expected 2 artists, 2 errors and 8 warnings, got %d, %d and %d",
+			report.Artists, report.Count(SeverityError), report.Count(SeverityWarning))
+	}
+}
+
+func TestCheckReportsIntegrityErrors(t *testing.T) {
+	report, err := Check(memorySource{artists: []models.Artist{{ID: 1, Name: "Queen", Members: []string{"Brian May"}, FirstAlbum: "14-12-1973"}}})
+	if err != nil {
+		t.Fatalf("Expected integrity problems in the report, not an error: %v", err)
+	}
+	if report.Count(SeverityError) != 3 {
+		t.Errorf("Expected missing locations, dates and relation as errors, got %+v", report.Issues)
+	}
+}
+
+func TestCheckDataset(t *testing.T) {
+	report, err := Check(DirSource{Dir: "testdata/dataset"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Count(SeverityError) != 0 {
+		t.Errorf("Expected the dataset to have no errors, got %+v", report.Issues)
+	}
+}
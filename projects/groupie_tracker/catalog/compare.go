@@ -0,0 +1,162 @@
+package catalog
+
+import (
+	"fmt"
+	"groupie_tracker/models"
+	"sort"
+	"time"
+)
+
+// MaxCompared is the largest number of artists Compare accepts
+const MaxCompared = 4
+
+// ArtistRef names an artist without the rest of its data
+type ArtistRef struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// ComparedArtist is one column of a comparison
+type ComparedArtist struct {
+	models.Artist
+	Concerts     int        `json:"concerts"`
+	FirstConcert *time.Time `json:"firstConcert,omitempty"`
+	LastConcert  *time.Time `json:"lastConcert,omitempty"`
+}
+
+// SharedLocation is a location where at least two of the artists played
+type SharedLocation struct {
+	Location string      `json:"location"`
+	Artists  []ArtistRef `json:"artists"`
+}
+
+// SameDay is a date on which at least two of the artists played
+type SameDay struct {
+	Date  time.Time     `json:"date"`
+	Shows []SameDayShow `json:"shows"`
+}
+
+// SameDayShow is where one of the artists played on a SameDay
+type SameDayShow struct {
+	Artist   ArtistRef `json:"artist"`
+	Location string    `json:"location"`
+}
+
+// TourOverlap is the period during which two artists were both touring,
+// measured from their first to their last concert
+type TourOverlap struct {
+	Artists [2]ArtistRef `json:"artists"`
+	From    time.Time    `json:"from"`
+	To      time.Time    `json:"to"`
+}
+
+// Comparison puts several artists side by side
+type Comparison struct {
+	Artists         []ComparedArtist `json:"artists"`
+	SharedLocations []SharedLocation `json:"sharedLocations"`
+	SameDays        []SameDay        `json:"sameDays"`
+	TourOverlaps    []TourOverlap    `json:"tourOverlaps"`
+}
+
+// Compare builds a comparison of the given artists, in the order given
+func (c *Catalog) Compare(ids []int) (Comparison, error) {
+	if len(ids) < 2 || len(ids) > MaxCompared {
+		return Comparison{}, fmt.Errorf("compare needs between 2 and %d artists, got %d", MaxCompared, len(ids))
+	}
+
+	cmp := Comparison{
+		SharedLocations: []SharedLocation{},
+		SameDays:        []SameDay{},
+		TourOverlaps:    []TourOverlap{},
+	}
+	refs := make(map[int]ArtistRef, len(ids))
+	byLocation := make(map[string][]ArtistRef)
+	byDate := make(map[time.Time][]SameDayShow)
+
+	// 1. One column per artist, collecting locations and dates on the way
+	for _, id := range ids {
+		artist, ok := c.Artist(id)
+		if !ok {
+			return Comparison{}, fmt.Errorf("artist %d not found", id)
+		}
+		ref := ArtistRef{ID: artist.ID, Name: artist.Name}
+		refs[id] = ref
+
+		concerts := c.Concerts(id)
+		column := ComparedArtist{Artist: artist, Concerts: len(concerts)}
+		if len(concerts) > 0 {
+			column.FirstConcert = &concerts[0].Date
+			column.LastConcert = &concerts[len(concerts)-1].Date
+		}
+		cmp.Artists = append(cmp.Artists, column)
+
+		for location := range c.Relations[id].DatesLocations {
+			byLocation[location] = append(byLocation[location], ref)
+		}
+		for _, concert := range concerts {
+			byDate[concert.Date] = append(byDate[concert.Date], SameDayShow{Artist: ref, Location: concert.Location})
+		}
+	}
+
+	// 2. Locations and dates shared by more than one artist
+	for location, artists := range byLocation {
+		if len(artists) > 1 {
+			cmp.SharedLocations = append(cmp.SharedLocations, SharedLocation{Location: location, Artists: artists})
+		}
+	}
+	sort.Slice(cmp.SharedLocations, func(i, j int) bool {
+		return cmp.SharedLocations[i].Location < cmp.SharedLocations[j].Location
+	})
+
+	for date, shows := range byDate {
+		if distinctArtists(shows) > 1 {
+			cmp.SameDays = append(cmp.SameDays, SameDay{Date: date, Shows: shows})
+		}
+	}
+	sort.Slice(cmp.SameDays, func(i, j int) bool {
+		return cmp.SameDays[i].Date.Before(cmp.SameDays[j].Date)
+	})
+
+	// 3. Pairwise overlap of the touring periods
+	for i, a := range cmp.Artists {
+		for _, b := range cmp.Artists[i+1:] {
+			if a.FirstConcert == nil || b.FirstConcert == nil {
+				continue
+			}
+			from := maxTime(*a.FirstConcert, *b.FirstConcert)
+			to := minTime(*a.LastConcert, *b.LastConcert)
+			if from.After(to) {
+				continue
+			}
+			cmp.TourOverlaps = append(cmp.TourOverlaps, TourOverlap{
+				Artists: [2]ArtistRef{refs[a.ID], refs[b.ID]},
+				From:    from,
+				To:      to,
+			})
+		}
+	}
+
+	return cmp, nil
+}
+
+func distinctArtists(shows []SameDayShow) int {
+	seen := make(map[int]bool)
+	for _, show := range shows {
+		seen[show.Artist.ID] = true
+	}
+	return len(seen)
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,45 @@
+package catalog
+
+import (
+	"testing"
+
+	"groupie_tracker/models"
+)
+
+func TestCompare(t *testing.T) {
+	cat := New(
+		[]models.Artist{{ID: 1, Name: "Queen"}, {ID: 2, Name: "SOJA"}},
+		nil,
+		nil,
+		[]models.Relation{
+			{ID: 1, DatesLocations: map[string][]string{"london-uk": {"01-01-2020"}, "paris-france": {"05-03-2020"}}},
+			{ID: 2, DatesLocations: map[string][]string{"london-uk": {"01-02-2020"}, "osaka-japan": {"05-03-2020"}}},
+		},
+	)
+
+	cmp, err := cat.Compare([]int{1, 2})
+	if err != nil {
+		t.Fatalf("Expected no error comparing, got: %v", err)
+	}
+
+	if len(cmp.SharedLocations) != 1 || cmp.SharedLocations[0].Location != "london-uk" {
+		t.Errorf("Expected london-uk as the only shared location, got %+v", cmp.SharedLocations)
+	}
+	if len(cmp.SameDays) != 1 || len(cmp.SameDays[0].Shows) != 2 {
+		t.Errorf("Expected one same-day pair on 05-03-2020, got %+v", cmp.SameDays)
+	}
+	if len(cmp.TourOverlaps) != 1 || cmp.TourOverlaps[0].From.Format(DateLayout) != "01-02-2020" {
+		t.Errorf("Expected tours to overlap from 01-02-2020, got %+v", cmp.TourOverlaps)
+	}
+}
+
+func TestCompareRejectsBadInput(t *testing.T) {
+	cat := testCatalog()
+
+	if _, err := cat.Compare([]int{1}); err == nil {
+		t.Error("Expected an error comparing a single artist")
+	}
+	if _, err := cat.Compare([]int{1, 42}); err == nil {
+		t.Error("Expected an error comparing an unknown artist")
+	}
+}
@@ -0,0 +1,59 @@
+package catalog
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// DateLayout is the day-month-year format used by the upstream API
+const DateLayout = "02-01-2006"
+
+// Concert is a single dated show of an artist, taken from the relation data
+type Concert struct {
+	ArtistID int       `json:"artistId"`
+	Location string    `json:"location"`
+	Date     time.Time `json:"date"`
+}
+
+// ParseDate parses an upstream date. The /dates endpoint marks some
+// dates with a leading '*', which is ignored.
+func ParseDate(s string) (time.Time, error) {
+	return time.Parse(DateLayout, strings.TrimPrefix(s, "*"))
+}
+
+// Concerts returns the concerts of an artist ordered by date, then location.
+// Dates that cannot be parsed are skipped.
+func (c *Catalog) Concerts(artistID int) []Concert {
+	var concerts []Concert
+	for location, dates := range c.Relations[artistID].DatesLocations {
+		for _, d := range dates {
+			date, err := ParseDate(d)
+			if err != nil {
+				continue
+			}
+			concerts = append(concerts, Concert{ArtistID: artistID, Location: location, Date: date})
+		}
+	}
+
+	sort.Slice(concerts, func(i, j int) bool {
+		if !concerts[i].Date.Equal(concerts[j].Date) {
+			return concerts[i].Date.Before(concerts[j].Date)
+		}
+		return concerts[i].Location < concerts[j].Location
+	})
+	return concerts
+}
+
+// Upcoming filters concerts to those on or after the day of now
+func Upcoming(concerts []Concert, now time.Time) []Concert {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	var upcoming []Concert
+	for _, concert := range concerts {
+		if !concert.Date.Before(today) {
+			upcoming = append(upcoming, concert)
+		}
+	}
+	return upcoming
+}
@@ -0,0 +1,175 @@
+package catalog
+
+import (
+	"fmt"
+	"groupie_tracker/models"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ChangeKind names what happened to an artist between two catalogs
+type ChangeKind string
+
+const (
+	ArtistAdded     ChangeKind = "artist_added"
+	ArtistRemoved   ChangeKind = "artist_removed"
+	ArtistUpdated   ChangeKind = "artist_updated"
+	LocationAdded   ChangeKind = "location_added"
+	LocationRemoved ChangeKind = "location_removed"
+	DateAdded       ChangeKind = "date_added"
+	DateRemoved     ChangeKind = "date_removed"
+)
+
+// Change is a single entry of a changelog
+type Change struct {
+	Kind       ChangeKind `json:"kind"`
+	ArtistID   int        `json:"artistId"`
+	ArtistName string     `json:"artistName"`
+	Field      string     `json:"field,omitempty"`
+	Location   string     `json:"location,omitempty"`
+	Date       string     `json:"date,omitempty"`
+	Old        string     `json:"old,omitempty"`
+	New        string     `json:"new,omitempty"`
+}
+
+// String describes the change in one line
+func (c Change) String() string {
+	switch c.Kind {
+	case ArtistAdded:
+		return fmt.Sprintf("New artist: %s", c.ArtistName)
+	case ArtistRemoved:
+		return fmt.Sprintf("Artist removed: %s", c.ArtistName)
+	case ArtistUpdated:
+		return fmt.Sprintf("%s: %s changed from %q to %q", c.ArtistName, c.Field, c.Old, c.New)
+	case LocationAdded:
+		return fmt.Sprintf("%s: new location %s", c.ArtistName, c.Location)
+	case LocationRemoved:
+		return fmt.Sprintf("%s: location %s removed", c.ArtistName, c.Location)
+	case DateAdded:
+		return fmt.Sprintf("%s: new concert in %s on %s", c.ArtistName, c.Location, c.Date)
+	case DateRemoved:
+		return fmt.Sprintf("%s: concert in %s on %s removed", c.ArtistName, c.Location, c.Date)
+	}
+	return string(c.Kind)
+}
+
+// Changelog lists everything that changed between two catalog refreshes
+type Changelog struct {
+	From    time.Time `json:"from"`
+	To      time.Time `json:"to"`
+	Changes []Change  `json:"changes"`
+}
+
+// Diff compares two catalogs and returns what changed from prev to next.
+// Changes are ordered by artist ID so the output is stable.
+func Diff(prev, next *Catalog) Changelog {
+	changelog := Changelog{From: prev.FetchedAt, To: next.FetchedAt}
+
+	oldArtists := make(map[int]models.Artist, len(prev.Artists))
+	for _, a := range prev.Artists {
+		oldArtists[a.ID] = a
+	}
+	newArtists := make(map[int]models.Artist, len(next.Artists))
+	for _, a := range next.Artists {
+		newArtists[a.ID] = a
+	}
+
+	ids := make([]int, 0, len(newArtists)+len(oldArtists))
+	for id := range newArtists {
+		ids = append(ids, id)
+	}
+	for id := range oldArtists {
+		if _, ok := newArtists[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+
+	for _, id := range ids {
+		before, inOld := oldArtists[id]
+		after, inNew := newArtists[id]
+
+		switch {
+		case !inOld:
+			changelog.Changes = append(changelog.Changes, Change{Kind: ArtistAdded, ArtistID: id, ArtistName: after.Name})
+		case !inNew:
+			changelog.Changes = append(changelog.Changes, Change{Kind: ArtistRemoved, ArtistID: id, ArtistName: before.Name})
+			continue
+		default:
+			changelog.Changes = append(changelog.Changes, diffArtist(before, after)...)
+		}
+
+		changelog.Changes = append(changelog.Changes, diffConcerts(after, prev.Relations[id], next.Relations[id])...)
+	}
+
+	return changelog
+}
+
+// diffArtist reports changed fields of an artist present in both catalogs
+func diffArtist(before, after models.Artist) []Change {
+	var changes []Change
+	field := func(name, from, to string) {
+		if from != to {
+			changes = append(changes, Change{
+				Kind: ArtistUpdated, ArtistID: after.ID, ArtistName: after.Name,
+				Field: name, Old: from, New: to,
+			})
+		}
+	}
+
+	field("name", before.Name, after.Name)
+	field("image", before.Image, after.Image)
+	field("members", strings.Join(before.Members, ", "), strings.Join(after.Members, ", "))
+	field("creationDate", fmt.Sprint(before.CreationDate), fmt.Sprint(after.CreationDate))
+	field("firstAlbum", before.FirstAlbum, after.FirstAlbum)
+	return changes
+}
+
+// diffConcerts reports added/removed locations and dates of an artist
+func diffConcerts(artist models.Artist, before, after models.Relation) []Change {
+	var changes []Change
+	change := func(kind ChangeKind, location, date string) {
+		changes = append(changes, Change{
+			Kind: kind, ArtistID: artist.ID, ArtistName: artist.Name,
+			Location: location, Date: date,
+		})
+	}
+
+	for _, location := range sortedKeys(after.DatesLocations) {
+		oldDates, existed := before.DatesLocations[location]
+		if !existed {
+			change(LocationAdded, location, "")
+		}
+		for _, date := range after.DatesLocations[location] {
+			if !slices.Contains(oldDates, date) {
+				change(DateAdded, location, date)
+			}
+		}
+	}
+
+	for _, location := range sortedKeys(before.DatesLocations) {
+		newDates, exists := after.DatesLocations[location]
+		if !exists {
+			change(LocationRemoved, location, "")
+			continue
+		}
+		for _, date := range before.DatesLocations[location] {
+			if !slices.Contains(newDates, date) {
+				change(DateRemoved, location, date)
+			}
+		}
+	}
+
+	return changes
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
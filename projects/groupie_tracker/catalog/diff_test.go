@@ -0,0 +1,47 @@
+package catalog
+
+import (
+	"testing"
+
+	"groupie_tracker/models"
+)
+
+func TestDiff(t *testing.T) {
+	prev := testCatalog()
+	next := New(
+		[]models.Artist{
+			{ID: 1, Name: "Queen", Members: []string{"Freddie Mercury", "Brian May"}, CreationDate: 1970},
+			{ID: 2, Name: "Pink Floyd"},
+		},
+		nil,
+		nil,
+		[]models.Relation{
+			{ID: 1, DatesLocations: map[string][]string{"paris-france": {"02-02-2021"}}},
+		},
+	)
+
+	changes := Diff(prev, next).Changes
+
+	want := []Change{
+		{Kind: ArtistUpdated, ArtistID: 1, ArtistName: "Queen", Field: "members", Old: "Freddie Mercury", New: "Freddie Mercury, Brian May"},
+		{Kind: LocationAdded, ArtistID: 1, ArtistName: "Queen", Location: "paris-france"},
+		{Kind: DateAdded, ArtistID: 1, ArtistName: "Queen", Location: "paris-france", Date: "02-02-2021"},
+		{Kind: LocationRemoved, ArtistID: 1, ArtistName: "Queen", Location: "london-uk"},
+		{Kind: ArtistAdded, ArtistID: 2, ArtistName: "Pink Floyd"},
+	}
+
+	if len(changes) != len(want) {
+		t.Fatalf("Expected %d changes, got %d: %+v", len(want), len(changes), changes)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("Change %d: expected %+v, got %+v", i, want[i], changes[i])
+		}
+	}
+}
+
+func TestDiffIdentical(t *testing.T) {
+	if changes := Diff(testCatalog(), testCatalog()).Changes; len(changes) != 0 {
+		t.Errorf("Expected no changes between identical catalogs, got %+v", changes)
+	}
+}
@@ -0,0 +1,41 @@
+package catalog
+
+import "strings"
+
+// SplitLocation splits an upstream location slug such as
+// "north_carolina-usa" into its city and country parts
+func SplitLocation(slug string) (city, country string) {
+	city, country, _ = strings.Cut(slug, "-")
+	return city, country
+}
+
+// FormatLocation turns a location slug into a readable name,
+// e.g. "north_carolina-usa" becomes "North Carolina, USA"
+func FormatLocation(slug string) string {
+	city, country := SplitLocation(slug)
+	if country == "" {
+		return formatPlace(city)
+	}
+	return formatPlace(city) + ", " + FormatCountry(country)
+}
+
+// FormatCountry turns the country part of a location slug into a
+// readable name, e.g. "new_zealand" becomes "New Zealand". Short country
+// parts like "usa" and "uk" are acronyms.
+func FormatCountry(country string) string {
+	if len(country) <= 3 {
+		return strings.ToUpper(country)
+	}
+	return formatPlace(country)
+}
+
+// formatPlace title-cases the words of a slug part
+func formatPlace(part string) string {
+	words := strings.Split(part, "_")
+	for i, w := range words {
+		if w != "" {
+			words[i] = strings.ToUpper(w[:1]) + w[1:]
+		}
+	}
+	return strings.Join(words, " ")
+}
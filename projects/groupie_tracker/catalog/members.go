@@ -0,0 +1,103 @@
+package catalog
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Member is a person appearing in the line-up of one or more artists.
+// The same name in several line-ups is taken to be the same person.
+type Member struct {
+	Slug    string      `json:"slug"`
+	Name    string      `json:"name"`
+	Artists []ArtistRef `json:"artists"`
+}
+
+// Slug turns a member name into its URL form, e.g. "Freddie Mercury"
+// becomes "freddie-mercury". Names differing only in case, spacing or
+// punctuation share a slug.
+func Slug(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		case r == '\'':
+			// "O'Shea" is "oshea", not "o-shea"
+		default:
+			dash = true
+		}
+	}
+	return b.String()
+}
+
+// Members returns every member of the catalog once, ordered by name.
+// A member's artists are in catalog order.
+func (c *Catalog) Members() []Member {
+	c.indexMembers()
+	return slices.Clone(c.members.list)
+}
+
+// Member returns the member with the given slug
+func (c *Catalog) Member(slug string) (Member, bool) {
+	c.indexMembers()
+	i, ok := c.members.bySlug[slug]
+	if !ok {
+		return Member{}, false
+	}
+	return c.members.list[i], true
+}
+
+// indexMembers gathers the members of the catalog the first time they
+// are needed. Catalogs are not modified once built, so the index stays
+// valid for the catalog's lifetime.
+func (c *Catalog) indexMembers() {
+	c.members.once.Do(func() {
+		c.members.list = c.gatherMembers()
+		c.members.bySlug = make(map[string]int, len(c.members.list))
+		for i, member := range c.members.list {
+			c.members.bySlug[member.Slug] = i
+		}
+	})
+}
+
+func (c *Catalog) gatherMembers() []Member {
+	bySlug := make(map[string]*Member)
+	var members []*Member
+
+	for _, artist := range c.Artists {
+		ref := ArtistRef{ID: artist.ID, Name: artist.Name}
+		for _, name := range artist.Members {
+			slug := Slug(name)
+			if slug == "" {
+				continue
+			}
+			member, ok := bySlug[slug]
+			if !ok {
+				member = &Member{Slug: slug, Name: strings.TrimSpace(name)}
+				bySlug[slug] = member
+				members = append(members, member)
+			}
+			// Guard against a name listed twice in one line-up
+			if n := len(member.Artists); n == 0 || member.Artists[n-1].ID != artist.ID {
+				member.Artists = append(member.Artists, ref)
+			}
+		}
+	}
+
+	sorted := make([]Member, len(members))
+	for i, member := range members {
+		sorted[i] = *member
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Slug < sorted[j].Slug
+	})
+	return sorted
+}
@@ -0,0 +1,84 @@
+package catalog
+
+import (
+	"encoding/json"
+	"testing"
+
+	"groupie_tracker/models"
+)
+
+func TestSlug(t *testing.T) {
+	tests := map[string]string{
+		"Freddie Mercury":     "freddie-mercury",
+		"  Patrick O'Shea ":   "patrick-oshea",
+		"Jean-Michel Jarre":   "jean-michel-jarre",
+		"Chester  Bennington": "chester-bennington",
+		"DJ S.K.Y.":           "dj-s-k-y",
+		"Björk":               "björk",
+	}
+
+	for name, want := range tests {
+		if got := Slug(name); got != want {
+			t.Errorf("Slug(%q) = %q, expected %q", name, got, want)
+		}
+	}
+}
+
+func TestMembers(t *testing.T) {
+	cat := New(
+		[]models.Artist{
+			{ID: 1, Name: "Genesis", Members: []string{"Phil Collins", "Peter Gabriel"}},
+			{ID: 2, Name: "Phil Collins", Members: []string{"phil collins"}},
+			{ID: 3, Name: "Brand X", Members: []string{"Phil  Collins", "John Goodsall", "John Goodsall"}},
+		},
+		nil, nil, nil,
+	)
+
+	members := cat.Members()
+	if len(members) != 3 {
+		t.Fatalf("Expected 3 distinct members, got %+v", members)
+	}
+	if members[0].Slug != "john-goodsall" || members[1].Slug != "peter-gabriel" || members[2].Slug != "phil-collins" {
+		t.Errorf("Expected members ordered by slug, got %+v", members)
+	}
+
+	phil, ok := cat.Member("phil-collins")
+	if !ok {
+		t.Fatal("Expected to find phil-collins")
+	}
+	if phil.Name != "Phil Collins" || len(phil.Artists) != 3 {
+		t.Errorf("Expected Phil Collins in 3 artists, got %+v", phil)
+	}
+
+	john, _ := cat.Member("john-goodsall")
+	if len(john.Artists) != 1 {
+		t.Errorf("Expected a name listed twice in one line-up to count once, got %+v", john.Artists)
+	}
+
+	if _, ok := cat.Member("nobody"); ok {
+		t.Error("Expected no member for an unknown slug")
+	}
+}
+
+// Catalogs restored from a snapshot are built without New
+func TestMembersOfDecodedCatalog(t *testing.T) {
+	data, err := json.Marshal(New([]models.Artist{{ID: 1, Name: "Queen", Members: []string{"Brian May"}}}, nil, nil, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cat Catalog
+	if err := json.Unmarshal(data, &cat); err != nil {
+		t.Fatal(err)
+	}
+
+	if brian, ok := cat.Member("brian-may"); !ok || brian.Artists[0].Name != "Queen" {
+		t.Errorf("Expected Brian May in Queen, got %+v %v", brian, ok)
+	}
+
+	// Callers get their own copy of the list
+	members := cat.Members()
+	members[0].Slug = "changed"
+	if _, ok := cat.Member("brian-may"); !ok || cat.Members()[0].Slug != "brian-may" {
+		t.Error("Expected the member index not to be changed through Members")
+	}
+}
@@ -0,0 +1,165 @@
+package catalog
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Weights says how much each kind of likeness counts towards the
+// similarity of two artists. Only their ratios matter.
+type Weights struct {
+	Cities float64 `json:"cities"` // concert cities in common
+	Tours  float64 `json:"tours"`  // touring at the same time
+	Era    float64 `json:"era"`    // formed around the same year
+	Size   float64 `json:"size"`   // line-ups of a similar size
+}
+
+// DefaultWeights favour where and when artists actually played
+var DefaultWeights = Weights{Cities: 0.4, Tours: 0.3, Era: 0.2, Size: 0.1}
+
+// eraYears is the creation year gap at which artists count as unrelated
+const eraYears = 30
+
+// ParseWeights reads weights written as "cities=0.4,tours=0.3,era=0.2,size=0.1".
+// Omitted weights are zero.
+func ParseWeights(s string) (Weights, error) {
+	var w Weights
+	fields := map[string]*float64{"cities": &w.Cities, "tours": &w.Tours, "era": &w.Era, "size": &w.Size}
+	for _, part := range strings.Split(s, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		field, known := fields[name]
+		if !ok || !known {
+			return Weights{}, fmt.Errorf("invalid weight %q, expected cities, tours, era or size=<number>", part)
+		}
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil || v < 0 || math.IsInf(v, 0) {
+			return Weights{}, fmt.Errorf("invalid weight %q, expected a non-negative number", part)
+		}
+		*field = v
+	}
+	if w.total() == 0 {
+		return Weights{}, fmt.Errorf("at least one weight must be positive")
+	}
+	return w, nil
+}
+
+func (w Weights) total() float64 {
+	return w.Cities + w.Tours + w.Era + w.Size
+}
+
+// Likeness is how alike two artists are on each criterion, from 0 to 1
+type Likeness struct {
+	Cities float64 `json:"cities"`
+	Tours  float64 `json:"tours"`
+	Era    float64 `json:"era"`
+	Size   float64 `json:"size"`
+}
+
+// score combines the criteria into one number from 0 to 1
+func (l Likeness) score(w Weights) float64 {
+	return (l.Cities*w.Cities + l.Tours*w.Tours + l.Era*w.Era + l.Size*w.Size) / w.total()
+}
+
+// Neighbor is an artist similar to another one
+type Neighbor struct {
+	Artist   ArtistRef `json:"artist"`
+	Score    float64   `json:"score"`
+	Likeness Likeness  `json:"likeness"`
+}
+
+// profile is what similarity is computed from, gathered once per artist
+type profile struct {
+	ref          ArtistRef
+	cities       map[string]bool
+	first, last  time.Time // touring period; zero without concerts
+	creationYear int
+	members      int
+}
+
+// Similar lists up to limit neighbors for every artist, most similar
+// first. Artists with nothing in common are left out.
+func (c *Catalog) Similar(w Weights, limit int) map[int][]Neighbor {
+	profiles := make([]profile, 0, len(c.Artists))
+	for _, artist := range c.Artists {
+		p := profile{
+			ref:          ArtistRef{ID: artist.ID, Name: artist.Name},
+			cities:       make(map[string]bool),
+			creationYear: artist.CreationDate,
+			members:      len(artist.Members),
+		}
+		concerts := c.Concerts(artist.ID)
+		for _, concert := range concerts {
+			p.cities[concert.Location] = true
+		}
+		if len(concerts) > 0 {
+			p.first, p.last = concerts[0].Date, concerts[len(concerts)-1].Date
+		}
+		profiles = append(profiles, p)
+	}
+
+	neighbors := make(map[int][]Neighbor, len(profiles))
+	for i, a := range profiles {
+		for _, b := range profiles[i+1:] {
+			l := likeness(a, b)
+			score := l.score(w)
+			if score <= 0 {
+				continue
+			}
+			neighbors[a.ref.ID] = append(neighbors[a.ref.ID], Neighbor{Artist: b.ref, Score: score, Likeness: l})
+			neighbors[b.ref.ID] = append(neighbors[b.ref.ID], Neighbor{Artist: a.ref, Score: score, Likeness: l})
+		}
+	}
+
+	for id, list := range neighbors {
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].Score != list[j].Score {
+				return list[i].Score > list[j].Score
+			}
+			return list[i].Artist.ID < list[j].Artist.ID
+		})
+		if len(list) > limit {
+			neighbors[id] = list[:limit]
+		}
+	}
+	return neighbors
+}
+
+func likeness(a, b profile) Likeness {
+	var l Likeness
+
+	// Jaccard index of the concert cities
+	shared := 0
+	for city := range a.cities {
+		if b.cities[city] {
+			shared++
+		}
+	}
+	if union := len(a.cities) + len(b.cities) - shared; union > 0 {
+		l.Cities = float64(shared) / float64(union)
+	}
+
+	// Share of the shorter touring period spent touring together
+	if !a.first.IsZero() && !b.first.IsZero() {
+		from, to := maxTime(a.first, b.first), minTime(a.last, b.last)
+		if !from.After(to) {
+			overlap := to.Sub(from).Hours()/24 + 1
+			shorter := math.Min(a.last.Sub(a.first).Hours(), b.last.Sub(b.first).Hours())/24 + 1
+			l.Tours = math.Min(overlap/shorter, 1)
+		}
+	}
+
+	if a.creationYear > 0 && b.creationYear > 0 {
+		gap := math.Abs(float64(a.creationYear - b.creationYear))
+		l.Era = math.Max(0, 1-gap/eraYears)
+	}
+
+	if a.members > 0 && b.members > 0 {
+		gap := math.Abs(float64(a.members - b.members))
+		l.Size = 1 - gap/math.Max(float64(a.members), float64(b.members))
+	}
+	return l
+}
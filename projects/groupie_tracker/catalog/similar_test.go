@@ -0,0 +1,77 @@
+package catalog
+
+import (
+	"math"
+	"testing"
+
+	"groupie_tracker/models"
+)
+
+func similarCatalog() *Catalog {
+	return New(
+		[]models.Artist{
+			{ID: 1, Name: "Queen", Members: []string{"A", "B", "C", "D"}, CreationDate: 1970},
+			{ID: 2, Name: "Queen Tribute", Members: []string{"E", "F", "G", "H"}, CreationDate: 1972},
+			{ID: 3, Name: "Solo Act", Members: []string{"I"}, CreationDate: 2010},
+			{ID: 4, Name: "Silent Band", Members: []string{"J", "K"}},
+		},
+		nil, nil,
+		[]models.Relation{
+			{ID: 1, DatesLocations: map[string][]string{"london-uk": {"01-01-2020"}, "paris-france": {"10-01-2020"}}},
+			{ID: 2, DatesLocations: map[string][]string{"london-uk": {"05-01-2020"}, "berlin-germany": {"20-01-2020"}}},
+			{ID: 3, DatesLocations: map[string][]string{"tokyo-japan": {"01-06-2021"}}},
+		},
+	)
+}
+
+func TestSimilar(t *testing.T) {
+	neighbors := similarCatalog().Similar(DefaultWeights, 2)
+
+	queen := neighbors[1]
+	if len(queen) != 2 || queen[0].Artist.Name != "Queen Tribute" {
+		t.Fatalf("Expected Queen Tribute first, got %+v", queen)
+	}
+
+	l := queen[0].Likeness
+	// 1 shared city of 3, tours overlapping for 6 of the 10 days of the shorter
+	want := Likeness{Cities: 1.0 / 3, Tours: 0.6, Era: 1 - 2.0/30, Size: 1}
+	if !near(l.Cities, want.Cities) || !near(l.Tours, want.Tours) || !near(l.Era, want.Era) || l.Size != want.Size {
+		t.Errorf("Likeness = %+v, expected %+v", l, want)
+	}
+	if !near(queen[0].Score, 0.4*want.Cities+0.3*want.Tours+0.2*want.Era+0.1) {
+		t.Errorf("Unexpected score %v", queen[0].Score)
+	}
+
+	// Relations are symmetric
+	if tribute := neighbors[2]; tribute[0].Artist.ID != 1 || !near(tribute[0].Score, queen[0].Score) {
+		t.Errorf("Expected Queen first for Queen Tribute, got %+v", tribute)
+	}
+}
+
+func TestSimilarLeavesOutUnrelatedArtists(t *testing.T) {
+	// Only shared cities count, and the solo act shares none
+	neighbors := similarCatalog().Similar(Weights{Cities: 1}, 5)
+	if len(neighbors[3]) != 0 || len(neighbors[4]) != 0 {
+		t.Errorf("Expected no neighbors without shared cities, got %+v", neighbors)
+	}
+	if len(neighbors[1]) != 1 {
+		t.Errorf("Expected one neighbor for Queen, got %+v", neighbors[1])
+	}
+}
+
+func TestParseWeights(t *testing.T) {
+	w, err := ParseWeights("cities=2, era=0.5")
+	if err != nil || w != (Weights{Cities: 2, Era: 0.5}) {
+		t.Errorf("Unexpected weights %+v, %v", w, err)
+	}
+
+	for _, bad := range []string{"", "cities", "genre=1", "size=-1", "era=x", "cities=0,size=0"} {
+		if _, err := ParseWeights(bad); err == nil {
+			t.Errorf("Expected an error for %q", bad)
+		}
+	}
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
@@ -0,0 +1,124 @@
+package catalog
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SchemaVersion is the snapshot layout written by this build.
+// Bump it whenever Catalog changes shape and register a migration below.
+const SchemaVersion = 1
+
+// ErrChecksum is returned when a snapshot's payload does not match its checksum
+var ErrChecksum = errors.New("snapshot checksum mismatch")
+
+// migration upgrades a catalog payload from version N to N+1
+type migration func(json.RawMessage) (json.RawMessage, error)
+
+// migrations is keyed by the version a migration upgrades from
+var migrations = map[int]migration{}
+
+// snapshotFile is the on-disk envelope around a catalog
+type snapshotFile struct {
+	Version  int             `json:"version"`
+	SavedAt  time.Time       `json:"savedAt"`
+	Checksum string          `json:"checksum"`
+	Catalog  json.RawMessage `json:"catalog"`
+}
+
+// checksum hashes the compact form of payload, so re-indenting the file
+// does not invalidate it
+func checksum(payload []byte) string {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, payload); err != nil {
+		buf.Reset()
+		buf.Write(payload)
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// SaveSnapshot writes the catalog to path atomically
+func SaveSnapshot(path string, c *Catalog) error {
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("failed to encode catalog: %w", err)
+	}
+
+	data, err := json.MarshalIndent(snapshotFile{
+		Version:  SchemaVersion,
+		SavedAt:  time.Now().UTC(),
+		Checksum: checksum(payload),
+		Catalog:  payload,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+
+	// Write to a temp file first so a crash never leaves a half-written snapshot
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// LoadSnapshot reads a snapshot written by SaveSnapshot, migrating it
+// forward if it was written by an older schema version. The catalog must
+// validate like a freshly loaded one.
+func LoadSnapshot(path string) (*Catalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file snapshotFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+
+	if file.Checksum != checksum(file.Catalog) {
+		return nil, ErrChecksum
+	}
+
+	if file.Version > SchemaVersion {
+		return nil, fmt.Errorf("snapshot version %d is newer than supported version %d", file.Version, SchemaVersion)
+	}
+
+	payload := file.Catalog
+	for v := file.Version; v < SchemaVersion; v++ {
+		migrate, ok := migrations[v]
+		if !ok {
+			return nil, fmt.Errorf("snapshot version %d can no longer be migrated", file.Version)
+		}
+		if payload, err = migrate(payload); err != nil {
+			return nil, fmt.Errorf("failed to migrate snapshot from version %d: %w", v, err)
+		}
+	}
+
+	var c Catalog
+	if err := json.Unmarshal(payload, &c); err != nil {
+		return nil, fmt.Errorf("failed to decode catalog: %w", err)
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
@@ -0,0 +1,71 @@
+package catalog
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"groupie_tracker/models"
+)
+
+func testCatalog() *Catalog {
+	return New(
+		[]models.Artist{{ID: 1, Name: "Queen", Members: []string{"Freddie Mercury"}, CreationDate: 1970}},
+		[]models.Locations{{ID: 1, Locations: []string{"london-uk"}}},
+		[]models.Dates{{ID: 1, Dates: []string{"*01-01-2020"}}},
+		[]models.Relation{{ID: 1, DatesLocations: map[string][]string{"london-uk": {"01-01-2020"}}}},
+	)
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+
+	if err := SaveSnapshot(path, testCatalog()); err != nil {
+		t.Fatalf("Expected no error saving snapshot, got: %v", err)
+	}
+
+	c, err := LoadSnapshot(path)
+	if err != nil {
+		t.Fatalf("Expected no error loading snapshot, got: %v", err)
+	}
+
+	artist, ok := c.Artist(1)
+	if !ok || artist.Name != "Queen" {
+		t.Errorf("Expected artist 1 to be Queen, got %+v", artist)
+	}
+	if got := c.Relations[1].DatesLocations["london-uk"]; len(got) != 1 {
+		t.Errorf("Expected one date for london-uk, got %v", got)
+	}
+}
+
+func TestSnapshotChecksumMismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	if err := SaveSnapshot(path, testCatalog()); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := os.ReadFile(path)
+	tampered := strings.Replace(string(data), "Queen", "Qveen", 1)
+	os.WriteFile(path, []byte(tampered), 0o644)
+
+	if _, err := LoadSnapshot(path); !errors.Is(err, ErrChecksum) {
+		t.Errorf("Expected ErrChecksum, got: %v", err)
+	}
+}
+
+func TestSnapshotNewerVersionRejected(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	if err := SaveSnapshot(path, testCatalog()); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := os.ReadFile(path)
+	future := strings.Replace(string(data), `"version": 1`, `"version": 99`, 1)
+	os.WriteFile(path, []byte(future), 0o644)
+
+	if _, err := LoadSnapshot(path); err == nil {
+		t.Error("Expected an error for a snapshot from a newer schema, got nil")
+	}
+}
@@ -0,0 +1,147 @@
+package catalog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"groupie_tracker/api"
+	"groupie_tracker/models"
+	"os"
+	"path/filepath"
+)
+
+// DataSource provides the four upstream resources a catalog is made of
+type DataSource interface {
+	Artists() ([]models.Artist, error)
+	Locations() (models.LocationsIndex, error)
+	Dates() (models.DatesIndex, error)
+	Relations() (models.RelationIndex, error)
+}
+
+// HTTPSource reads the resources from the upstream API
+type HTTPSource struct{}
+
+func (HTTPSource) Artists() ([]models.Artist, error)         { return api.GetArtists() }
+func (HTTPSource) Locations() (models.LocationsIndex, error) { return api.GetLocationsIndex() }
+func (HTTPSource) Dates() (models.DatesIndex, error)         { return api.GetDatesIndex() }
+func (HTTPSource) Relations() (models.RelationIndex, error)  { return api.GetRelationIndex() }
+
+// DirSource reads the resources from a directory holding artists.json,
+// locations.json, dates.json and relation.json, in the same shapes as
+// the upstream endpoints of the same names
+type DirSource struct {
+	Dir string
+}
+
+func (s DirSource) Artists() ([]models.Artist, error) {
+	var artists []models.Artist
+	err := s.read("artists.json", &artists)
+	return artists, err
+}
+
+func (s DirSource) Locations() (models.LocationsIndex, error) {
+	var index models.LocationsIndex
+	err := s.read("locations.json", &index)
+	return index, err
+}
+
+func (s DirSource) Dates() (models.DatesIndex, error) {
+	var index models.DatesIndex
+	err := s.read("dates.json", &index)
+	return index, err
+}
+
+func (s DirSource) Relations() (models.RelationIndex, error) {
+	var index models.RelationIndex
+	err := s.read("relation.json", &index)
+	return index, err
+}
+
+func (s DirSource) read(name string, target any) error {
+	data, err := os.ReadFile(filepath.Join(s.Dir, name))
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", name, err)
+	}
+	return nil
+}
+
+// resources are the four resources of a data source, as read
+type resources struct {
+	artists   []models.Artist
+	locations []models.Locations
+	dates     []models.Dates
+	relations []models.Relation
+}
+
+// readAll reads every resource of a data source
+func readAll(src DataSource) (resources, error) {
+	var res resources
+
+	artists, err := src.Artists()
+	if err != nil {
+		return res, fmt.Errorf("artists: %w", err)
+	}
+	res.artists = artists
+
+	locations, err := src.Locations()
+	if err != nil {
+		return res, fmt.Errorf("locations: %w", err)
+	}
+	res.locations = locations.Index
+
+	dates, err := src.Dates()
+	if err != nil {
+		return res, fmt.Errorf("dates: %w", err)
+	}
+	res.dates = dates.Index
+
+	relations, err := src.Relations()
+	if err != nil {
+		return res, fmt.Errorf("relations: %w", err)
+	}
+	res.relations = relations.Index
+
+	return res, nil
+}
+
+// integrity lists the referential integrity problems of the resources
+func (res resources) integrity() []Problem {
+	// Duplicate entries would silently overwrite each other in the catalog
+	problems := duplicates("locations", ids(res.locations, func(l models.Locations) int { return l.ID }))
+	problems = append(problems, duplicates("dates", ids(res.dates, func(d models.Dates) int { return d.ID }))...)
+	problems = append(problems, duplicates("relation", ids(res.relations, func(r models.Relation) int { return r.ID }))...)
+
+	var verr *ValidationError
+	if errors.As(res.catalog().Validate(), &verr) {
+		problems = append(problems, verr.Problems...)
+	}
+	return problems
+}
+
+func (res resources) catalog() *Catalog {
+	return New(res.artists, res.locations, res.dates, res.relations)
+}
+
+// Load reads the whole catalog from a data source and checks that it
+// holds together, see Validate
+func Load(src DataSource) (*Catalog, error) {
+	res, err := readAll(src)
+	if err != nil {
+		return nil, err
+	}
+	if problems := res.integrity(); len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+	return res.catalog(), nil
+}
+
+func ids[T any](entries []T, id func(T) int) []int {
+	out := make([]int, len(entries))
+	for i, e := range entries {
+		out[i] = id(e)
+	}
+	return out
+}
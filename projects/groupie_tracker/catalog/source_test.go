@@ -0,0 +1,248 @@
+package catalog
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"groupie_tracker/models"
+)
+
+func TestLoadDirSource(t *testing.T) {
+	c, err := Load(DirSource{Dir: "testdata/dataset"})
+	if err != nil {
+		t.Fatalf("Expected the dataset to load, got: %v", err)
+	}
+
+	if len(c.Artists) != 2 || c.Artists[1].Name != "Desert Radio" {
+		t.Errorf("Expected 2 artists, got %+v", c.Artists)
+	}
+	if got := c.Concerts(1); len(got) != 2 || got[0].Location != "cairo-egypt" {
+		t.Errorf("Expected 2 concerts starting in Cairo, got %+v", got)
+	}
+}
+
+func TestLoadDirSourceMissingFile(t *testing.T) {
+	_, err := Load(DirSource{Dir: t.TempDir()})
+	if err == nil || !strings.HasPrefix(err.Error(), "artists:") {
+		t.Errorf("Expected an error naming the artists file, got: %v", err)
+	}
+}
+
+// memorySource is a DataSource over in-memory resources
+type memorySource struct {
+	artists   []models.Artist
+	locations []models.Locations
+	dates     []models.Dates
+	relations []models.Relation
+}
+
+func (s memorySource) Artists() ([]models.Artist, error) { return s.artists, nil }
+func (s memorySource) Locations() (models.LocationsIndex, error) {
+	return models.LocationsIndex{Index: s.locations}, nil
+}
+func (s memorySource) Dates() (models.DatesIndex, error) {
+	return models.DatesIndex{Index: s.dates}, nil
+}
+func (s memorySource) Relations() (models.RelationIndex, error) {
+	return models.RelationIndex{Index: s.relations}, nil
+}
+
+func TestLoadReferentialIntegrity(t *testing.T) {
+	src := memorySource{
+		artists:   []models.Artist{{ID: 1, Name: "Queen"}, {ID: 2, Name: "SOJA"}, {ID: 2, Name: "SOJA again"}},
+		locations: []models.Locations{{ID: 1}, {ID: 2}, {ID: 7}},
+		dates:     []models.Dates{{ID: 1}, {ID: 1}, {ID: 2}},
+		relations: []models.Relation{{ID: 1}},
+	}
+
+	_, err := Load(src)
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Expected a *ValidationError, got: %v", err)
+	}
+
+	want := []string{
+		"dates: artist 1: ID is listed more than once",
+		"artists: artist 2: ID is listed more than once",
+		"locations: artist 7: entry has no matching artist",
+		"relation: artist 2: artist has no entry",
+	}
+	var got []string
+	for _, p := range verr.Problems {
+		got = append(got, p.String())
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Expected problems:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+	if !strings.Contains(err.Error(), "and 1 more") {
+		t.Errorf("Expected the error message to summarize, got: %v", err)
+	}
+}
+
+func TestStoreRefreshKeepsCatalogOnInvalidData(t *testing.T) {
+	s := NewStore(memorySource{artists: []models.Artist{{ID: 1, Name: "Queen"}}}, "")
+	s.Set(testCatalog())
+
+	if err := s.Refresh(); err == nil {
+		t.Fatal("Expected refresh to fail validation")
+	}
+	if c, err := s.Current(); err != nil || len(c.Artists) != 1 || c.Artists[0].Name != "Queen" {
+		t.Errorf("Expected the previous catalog to be kept, got %v, %v", c, err)
+	}
+}
+
+func TestStoreOnRefresh(t *testing.T) {
+	src := memorySource{
+		artists:   []models.Artist{{ID: 1, Name: "Queen"}, {ID: 2, Name: "SOJA"}},
+		locations: []models.Locations{{ID: 1}, {ID: 2}},
+		dates:     []models.Dates{{ID: 1}, {ID: 2}},
+		relations: []models.Relation{{ID: 1}, {ID: 2}},
+	}
+	s := NewStore(src, "")
+
+	var changes []int
+	s.OnRefresh(func(c *Catalog, changelog Changelog) {
+		changes = append(changes, len(changelog.Changes))
+	})
+
+	s.Refresh() // first load
+	s.Set(testCatalog())
+	s.Refresh() // SOJA added, Queen's concerts gone
+
+	if len(changes) != 2 || changes[0] != 0 || changes[1] == 0 {
+		t.Errorf("Expected listeners to see no changes, then some, got %v", changes)
+	}
+}
+
+func TestStoreStatus(t *testing.T) {
+	s := NewStore(memorySource{artists: []models.Artist{{ID: 1, Name: "Queen"}}}, "catalog.json")
+	if status := s.Status(); !status.LastAttempt.IsZero() || status.SnapshotPath != "catalog.json" {
+		t.Errorf("Expected no attempt yet, got %+v", status)
+	}
+
+	s.Refresh()
+	status := s.Status()
+	if status.LastAttempt.IsZero() || status.LastError == nil || !status.LastRefresh.IsZero() {
+		t.Errorf("Expected a failed attempt, got %+v", status)
+	}
+}
+
+func TestStoreCountsReadsBySource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.json")
+	if err := SaveSnapshot(path, testCatalog()); err != nil {
+		t.Fatal(err)
+	}
+	src := memorySource{
+		artists:   []models.Artist{{ID: 1, Name: "Queen"}},
+		locations: []models.Locations{{ID: 1}},
+		dates:     []models.Dates{{ID: 1}},
+		relations: []models.Relation{{ID: 1}},
+	}
+	s := NewStore(src, path)
+
+	snapshot, refresh := catalogReads.With("snapshot").Value(), catalogReads.With("refresh").Value()
+	if err := s.LoadSnapshot(); err != nil {
+		t.Fatal(err)
+	}
+	s.Current()
+	if err := s.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	s.Current()
+	s.Current()
+
+	if got := catalogReads.With("snapshot").Value() - snapshot; got != 1 {
+		t.Errorf("Expected 1 read of the snapshot, got %d", got)
+	}
+	if got := catalogReads.With("refresh").Value() - refresh; got != 2 {
+		t.Errorf("Expected 2 reads of refreshed data, got %d", got)
+	}
+}
+
+func TestStoreLoadSnapshotNotifiesListeners(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.json")
+	s := NewStore(memorySource{}, path)
+	var loaded []*Catalog
+	s.OnRefresh(func(c *Catalog, _ Changelog) {
+		loaded = append(loaded, c)
+	})
+
+	invalid := New([]models.Artist{{ID: 1, Name: "Queen"}}, nil, nil, nil)
+	if err := SaveSnapshot(path, invalid); err != nil {
+		t.Fatal(err)
+	}
+	var verr *ValidationError
+	if err := s.LoadSnapshot(); !errors.As(err, &verr) {
+		t.Errorf("Expected a snapshot that does not validate to be refused, got %v", err)
+	}
+	if _, err := s.Current(); err != ErrNotLoaded || len(loaded) != 0 {
+		t.Errorf("Expected nothing to be served, got %v and %d notifications", err, len(loaded))
+	}
+
+	if err := SaveSnapshot(path, testCatalog()); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.LoadSnapshot(); err != nil {
+		t.Fatal(err)
+	}
+	if c, _ := s.Current(); len(loaded) != 1 || loaded[0] != c {
+		t.Errorf("Expected listeners to get the snapshot, got %d notifications", len(loaded))
+	}
+}
+
+// slowSource counts the loads that overlap another one
+type slowSource struct {
+	memorySource
+	running, overlaps *atomic.Int32
+}
+
+func (s slowSource) Artists() ([]models.Artist, error) {
+	if s.running.Add(1) > 1 {
+		s.overlaps.Add(1)
+	}
+	defer s.running.Add(-1)
+	time.Sleep(5 * time.Millisecond)
+	return s.memorySource.Artists()
+}
+
+func TestStoreRefreshesOneAtATime(t *testing.T) {
+	src := slowSource{
+		memorySource: memorySource{
+			artists:   []models.Artist{{ID: 1, Name: "Queen"}},
+			locations: []models.Locations{{ID: 1}},
+			dates:     []models.Dates{{ID: 1}},
+			relations: []models.Relation{{ID: 1}},
+		},
+		running:  new(atomic.Int32),
+		overlaps: new(atomic.Int32),
+	}
+	s := NewStore(src, "")
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Go(func() { s.Refresh() })
+	}
+	wg.Wait()
+
+	if overlaps := src.overlaps.Load(); overlaps != 0 {
+		t.Errorf("Expected refreshes to run one at a time, got %d overlapping", overlaps)
+	}
+}
+
+func TestStoreHitRatio(t *testing.T) {
+	s := NewStore(memorySource{}, "")
+	s.Current() // a miss
+	s.Set(testCatalog())
+	s.Current() // a hit
+
+	reads := catalogReads.With("snapshot").Value() + catalogReads.With("refresh").Value()
+	misses := cacheMisses.With().Value()
+	if got, want := hitRatio(), float64(reads)/float64(reads+misses); got != want || got <= 0 || got >= 1 {
+		t.Errorf("Expected a hit ratio of %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,112 @@
+package catalog
+
+import (
+	"sort"
+	"strconv"
+)
+
+// topPlaces is how many countries and cities Stats ranks
+const topPlaces = 10
+
+// Count is one bar of a statistic
+type Count struct {
+	Label string `json:"label"`
+	Value int    `json:"value"`
+}
+
+// Stats are aggregate figures over the whole catalog
+type Stats struct {
+	Artists   int `json:"artists"`
+	Members   int `json:"members"`
+	Concerts  int `json:"concerts"`
+	Countries int `json:"countries"`
+	Cities    int `json:"cities"`
+
+	Decades         []Count `json:"decades"`         // artists by formation decade, e.g. "1970"
+	ConcertsPerYear []Count `json:"concertsPerYear"` // e.g. "2019"
+	TopCountries    []Count `json:"topCountries"`    // by concerts, readable names
+	TopCities       []Count `json:"topCities"`       // by concerts, readable names
+	BandSizes       []Count `json:"bandSizes"`       // artists by number of members, e.g. "4"
+}
+
+// Stats aggregates the catalog. Series over time or size are in order
+// and include the empty steps in between; top lists are by count.
+func (c *Catalog) Stats() Stats {
+	s := Stats{
+		Artists: len(c.Artists),
+		Members: len(c.Members()),
+	}
+
+	decades := make(map[int]int)
+	sizes := make(map[int]int)
+	years := make(map[int]int)
+	countries := make(map[string]int)
+	cities := make(map[string]int)
+
+	for _, artist := range c.Artists {
+		if artist.CreationDate > 0 {
+			decades[artist.CreationDate/10*10]++
+		}
+		sizes[len(artist.Members)]++
+
+		for _, concert := range c.Concerts(artist.ID) {
+			s.Concerts++
+			years[concert.Date.Year()]++
+			_, country := SplitLocation(concert.Location)
+			countries[FormatCountry(country)]++
+			cities[FormatLocation(concert.Location)]++
+		}
+	}
+
+	s.Countries, s.Cities = len(countries), len(cities)
+	s.Decades = series(decades, 10)
+	s.ConcertsPerYear = series(years, 1)
+	s.BandSizes = series(sizes, 1)
+	s.TopCountries = top(countries, topPlaces)
+	s.TopCities = top(cities, topPlaces)
+	return s
+}
+
+// series lists counts keyed by number from the lowest key to the highest,
+// every step apart, with zeros for missing steps
+func series(counts map[int]int, step int) []Count {
+	out := []Count{}
+	if len(counts) == 0 {
+		return out
+	}
+
+	lo, hi := 0, 0
+	first := true
+	for k := range counts {
+		if first || k < lo {
+			lo = k
+		}
+		if first || k > hi {
+			hi = k
+		}
+		first = false
+	}
+
+	for k := lo; k <= hi; k += step {
+		out = append(out, Count{Label: strconv.Itoa(k), Value: counts[k]})
+	}
+	return out
+}
+
+// top lists the n largest counts, ties by label
+func top(counts map[string]int, n int) []Count {
+	out := make([]Count, 0, len(counts))
+	for label, value := range counts {
+		out = append(out, Count{Label: label, Value: value})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Value != out[j].Value {
+			return out[i].Value > out[j].Value
+		}
+		return out[i].Label < out[j].Label
+	})
+	if len(out) > n {
+		out = out[:n]
+	}
+	return out
+}
@@ -0,0 +1,59 @@
+package catalog
+
+import (
+	"reflect"
+	"testing"
+
+	"groupie_tracker/models"
+)
+
+func TestStats(t *testing.T) {
+	cat := New(
+		[]models.Artist{
+			{ID: 1, Name: "Queen", Members: []string{"Freddie Mercury", "Brian May", "Roger Taylor", "John Deacon"}, CreationDate: 1970},
+			{ID: 2, Name: "Pink Floyd", Members: []string{"Roger Waters", "David Gilmour", "Nick Mason", "Richard Wright"}, CreationDate: 1965},
+			{ID: 3, Name: "Bjork", Members: []string{"Bjork"}, CreationDate: 1993},
+		},
+		nil, nil,
+		[]models.Relation{
+			{ID: 1, DatesLocations: map[string][]string{"london-uk": {"01-01-2018", "02-01-2020"}, "new_york-usa": {"03-01-2020"}}},
+			{ID: 2, DatesLocations: map[string][]string{"london-uk": {"04-04-2020"}}},
+			{ID: 3, DatesLocations: map[string][]string{"reykjavik-iceland": {"05-05-2020"}}},
+		},
+	)
+
+	s := cat.Stats()
+	if s.Artists != 3 || s.Members != 9 || s.Concerts != 5 || s.Countries != 3 || s.Cities != 3 {
+		t.Errorf("Unexpected totals: %+v", s)
+	}
+
+	// Series run from the first step to the last, gaps included
+	wantDecades := []Count{{"1960", 1}, {"1970", 1}, {"1980", 0}, {"1990", 1}}
+	if !reflect.DeepEqual(s.Decades, wantDecades) {
+		t.Errorf("Decades = %v, expected %v", s.Decades, wantDecades)
+	}
+	wantYears := []Count{{"2018", 1}, {"2019", 0}, {"2020", 4}}
+	if !reflect.DeepEqual(s.ConcertsPerYear, wantYears) {
+		t.Errorf("ConcertsPerYear = %v, expected %v", s.ConcertsPerYear, wantYears)
+	}
+	wantSizes := []Count{{"1", 1}, {"2", 0}, {"3", 0}, {"4", 2}}
+	if !reflect.DeepEqual(s.BandSizes, wantSizes) {
+		t.Errorf("BandSizes = %v, expected %v", s.BandSizes, wantSizes)
+	}
+
+	// Top lists are by count, ties by name
+	wantCountries := []Count{{"UK", 3}, {"Iceland", 1}, {"USA", 1}}
+	if !reflect.DeepEqual(s.TopCountries, wantCountries) {
+		t.Errorf("TopCountries = %v, expected %v", s.TopCountries, wantCountries)
+	}
+	if s.TopCities[0] != (Count{"London, UK", 3}) {
+		t.Errorf("Expected London first, got %v", s.TopCities)
+	}
+}
+
+func TestStatsEmpty(t *testing.T) {
+	s := New(nil, nil, nil, nil).Stats()
+	if s.Artists != 0 || len(s.Decades) != 0 || len(s.TopCities) != 0 {
+		t.Errorf("Expected empty stats, got %+v", s)
+	}
+}
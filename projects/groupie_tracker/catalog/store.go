@@ -0,0 +1,279 @@
+package catalog
+
+import (
+	"errors"
+	"groupie_tracker/metrics"
+	"log"
+	"sync"
+	"time"
+)
+
+// ErrNotLoaded is returned when no catalog has been fetched or restored yet
+var ErrNotLoaded = errors.New("catalog not loaded yet")
+
+var (
+	catalogReads = metrics.NewCounterVec("groupie_catalog_reads_total", "Requests served a catalog, by where it came from (refresh or snapshot).", "source")
+	cacheMisses  = metrics.NewCounterVec("groupie_catalog_misses_total", "Requests that found no catalog loaded.")
+	_            = metrics.NewGaugeFunc("groupie_catalog_hit_ratio", "Share of requests served a catalog: reads/(reads+misses), 0 before the first request.", hitRatio)
+)
+
+// hitRatio is the share of Current calls that found a catalog loaded
+func hitRatio() float64 {
+	reads := catalogReads.With("snapshot").Value() + catalogReads.With("refresh").Value()
+	total := reads + cacheMisses.With().Value()
+	if total == 0 {
+		return 0
+	}
+	return float64(reads) / float64(total)
+}
+
+// maxChangelogs bounds how many refresh changelogs the store remembers
+const maxChangelogs = 100
+
+// Store holds the current catalog and keeps it fresh in the background.
+// It is safe for concurrent use.
+type Store struct {
+	refreshMu    sync.Mutex // serializes Refresh and LoadSnapshot
+	mu           sync.RWMutex
+	current      *Catalog
+	fromSnapshot bool // current was restored rather than refreshed
+	lastErr      error
+	lastRefresh  time.Time
+	lastAttempt  time.Time
+	lastDuration time.Duration
+	changelogs   []Changelog
+	listeners    []func(*Catalog, Changelog)
+	source       DataSource
+	snapshotPath string
+}
+
+// NewStore creates an empty store refreshed from source. If snapshotPath
+// is not empty, every successful refresh is persisted there.
+func NewStore(source DataSource, snapshotPath string) *Store {
+	return &Store{source: source, snapshotPath: snapshotPath}
+}
+
+// Current returns the latest catalog, or ErrNotLoaded
+func (s *Store) Current() (*Catalog, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.current == nil {
+		cacheMisses.With().Inc()
+		return nil, ErrNotLoaded
+	}
+	if s.fromSnapshot {
+		catalogReads.With("snapshot").Inc()
+	} else {
+		catalogReads.With("refresh").Inc()
+	}
+	return s.current, nil
+}
+
+// LastError returns the error from the most recent refresh, if any
+func (s *Store) LastError() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.lastErr
+}
+
+// LastRefresh returns when the catalog was last fetched successfully
+func (s *Store) LastRefresh() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.lastRefresh
+}
+
+// Status is the state of a store at a glance
+type Status struct {
+	LastRefresh  time.Time     // last successful refresh
+	LastAttempt  time.Time     // last refresh, successful or not
+	LastDuration time.Duration // time the data source took on the last attempt
+	LastError    error
+	Changelogs   int    // refresh changelogs remembered
+	SnapshotPath string // empty when snapshots are disabled
+}
+
+// Status reports how fresh the store is and how the last refresh went
+func (s *Store) Status() Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return Status{
+		LastRefresh:  s.lastRefresh,
+		LastAttempt:  s.lastAttempt,
+		LastDuration: s.lastDuration,
+		LastError:    s.lastErr,
+		Changelogs:   len(s.changelogs),
+		SnapshotPath: s.snapshotPath,
+	}
+}
+
+// ExportMetrics publishes where the served catalog came from and the
+// refresh times of the store
+func (s *Store) ExportMetrics() {
+	metrics.NewGaugeFunc(
+		"groupie_catalog_from_snapshot",
+		"1 while the catalog served was restored from the snapshot because no refresh has succeeded since.",
+		func() float64 {
+			s.mu.RLock()
+			defer s.mu.RUnlock()
+			if s.current != nil && s.fromSnapshot {
+				return 1
+			}
+			return 0
+		},
+	)
+	metrics.NewGaugeFunc(
+		"groupie_catalog_last_refresh_timestamp_seconds",
+		"Unix time of the last successful catalog refresh.",
+		func() float64 {
+			last := s.LastRefresh()
+			if last.IsZero() {
+				return 0
+			}
+			return float64(last.Unix())
+		},
+	)
+	metrics.NewGaugeFunc(
+		"groupie_catalog_last_refresh_duration_seconds",
+		"Time the data source took on the last refresh attempt.",
+		func() float64 {
+			return s.Status().LastDuration.Seconds()
+		},
+	)
+}
+
+// Set swaps in a catalog obtained elsewhere
+func (s *Store) Set(c *Catalog) {
+	s.mu.Lock()
+	s.current = c
+	s.mu.Unlock()
+}
+
+// OnRefresh registers fn to be called after every successful refresh or
+// snapshot load with the new catalog and what changed. The changelog is
+// empty for the first load. fn runs on the refreshing goroutine and must
+// not block.
+func (s *Store) OnRefresh(fn func(*Catalog, Changelog)) {
+	s.mu.Lock()
+	s.listeners = append(s.listeners, fn)
+	s.mu.Unlock()
+}
+
+// LoadSnapshot restores the catalog from the snapshot file and swaps it
+// in like a refresh. On failure, including a snapshot that does not
+// validate, the previous catalog is kept.
+func (s *Store) LoadSnapshot() error {
+	return s.LoadSnapshotFile(s.snapshotPath)
+}
+
+// LoadSnapshotFile is LoadSnapshot from another file, such as an older
+// snapshot kept by an operator. Refreshes still save to the store's own
+// snapshot file.
+func (s *Store) LoadSnapshotFile(path string) error {
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+
+	c, err := LoadSnapshot(path)
+	if err != nil {
+		return err
+	}
+	s.swap(c, true)
+	return nil
+}
+
+// Refresh loads the catalog from the data source and swaps it in.
+// On failure, including a catalog that does not validate, the previous
+// catalog is kept.
+func (s *Store) Refresh() error {
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+
+	start := time.Now()
+	c, err := Load(s.source)
+
+	s.mu.Lock()
+	s.lastAttempt = start
+	s.lastDuration = time.Since(start)
+	s.lastErr = err
+	if err == nil {
+		s.lastRefresh = time.Now()
+	}
+	s.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+	s.swap(c, false)
+
+	if s.snapshotPath != "" {
+		if err := SaveSnapshot(s.snapshotPath, c); err != nil {
+			log.Printf("Error saving snapshot: %v", err)
+		}
+	}
+	return nil
+}
+
+// swap serves c, records what changed and tells the listeners. The
+// caller must hold s.refreshMu, so listeners see catalogs in the order
+// they are served.
+func (s *Store) swap(c *Catalog, fromSnapshot bool) {
+	var changelog Changelog
+	s.mu.Lock()
+	if s.current != nil {
+		changelog = Diff(s.current, c)
+		s.record(changelog)
+	}
+	s.current = c
+	s.fromSnapshot = fromSnapshot
+	listeners := s.listeners
+	s.mu.Unlock()
+
+	for _, fn := range listeners {
+		fn(c, changelog)
+	}
+}
+
+// record keeps a non-empty changelog, dropping the oldest past the limit.
+// The caller must hold s.mu.
+func (s *Store) record(changelog Changelog) {
+	if len(changelog.Changes) == 0 {
+		return
+	}
+	s.changelogs = append(s.changelogs, changelog)
+	if len(s.changelogs) > maxChangelogs {
+		s.changelogs = s.changelogs[len(s.changelogs)-maxChangelogs:]
+	}
+}
+
+// ChangesSince returns the changelogs of refreshes after since, oldest first
+func (s *Store) ChangesSince(since time.Time) []Changelog {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []Changelog
+	for _, changelog := range s.changelogs {
+		if changelog.To.After(since) {
+			result = append(result, changelog)
+		}
+	}
+	return result
+}
+
+// Run refreshes the catalog every interval until stop is closed
+func (s *Store) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.Refresh(); err != nil {
+				log.Printf("Error refreshing catalog: %v", err)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
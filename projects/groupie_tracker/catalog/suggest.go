@@ -0,0 +1,140 @@
+package catalog
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Suggestion is one search-as-you-type completion
+type Suggestion struct {
+	Text     string `json:"text"`
+	Type     string `json:"type"`
+	ArtistID int    `json:"artistId,omitempty"` // 0 when several artists share it
+	rank     int
+}
+
+// How well a suggestion matches, best first
+const (
+	rankExact = iota
+	rankPrefix
+	rankWordPrefix
+	rankSubstring
+)
+
+// typeOrder breaks ties between equally ranked suggestions
+var typeOrder = map[string]int{
+	"artist":        0,
+	"member":        1,
+	"location":      2,
+	"first album":   3,
+	"creation date": 4,
+}
+
+// Suggest returns up to limit completions for a partial query. Matches
+// at the start of the text rank above matches at the start of a later
+// word, which rank above matches anywhere else. Locations and creation
+// dates shared by several artists are suggested once.
+func (c *Catalog) Suggest(query string, limit int) []Suggestion {
+	q := strings.ToLower(strings.TrimSpace(query))
+	if q == "" || limit <= 0 {
+		return nil
+	}
+
+	seen := make(map[string]int) // type + text -> index in suggestions
+	var suggestions []Suggestion
+	add := func(text, kind string, artistID int, rank int) {
+		key := kind + "\x00" + text
+		if i, ok := seen[key]; ok {
+			// Same text from another artist: keep it but drop the link
+			if suggestions[i].ArtistID != artistID {
+				suggestions[i].ArtistID = 0
+			}
+			return
+		}
+		seen[key] = len(suggestions)
+		suggestions = append(suggestions, Suggestion{Text: text, Type: kind, ArtistID: artistID, rank: rank})
+	}
+	try := func(text, kind string, artistID int) {
+		if rank, ok := matchRank(text, q); ok {
+			add(text, kind, artistID, rank)
+		}
+	}
+
+	for _, artist := range c.Artists {
+		try(artist.Name, "artist", artist.ID)
+		for _, member := range artist.Members {
+			try(member, "member", artist.ID)
+		}
+		for _, location := range c.Locations[artist.ID].Locations {
+			// Match the slug too, so "new_york" finds "New York, USA"
+			name := FormatLocation(location)
+			rank, ok := matchRank(name, q)
+			if slugRank, slugOK := matchRank(location, q); slugOK && (!ok || slugRank < rank) {
+				rank, ok = slugRank, true
+			}
+			if ok {
+				add(name, "location", artist.ID, rank)
+			}
+		}
+		try(artist.FirstAlbum, "first album", artist.ID)
+		try(strconv.Itoa(artist.CreationDate), "creation date", artist.ID)
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		a, b := suggestions[i], suggestions[j]
+		if a.rank != b.rank {
+			return a.rank < b.rank
+		}
+		if typeOrder[a.Type] != typeOrder[b.Type] {
+			return typeOrder[a.Type] < typeOrder[b.Type]
+		}
+		return strings.ToLower(a.Text) < strings.ToLower(b.Text)
+	})
+
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions
+}
+
+// matchRank reports how well text matches the lower-cased query q
+func matchRank(text, q string) (int, bool) {
+	t := strings.ToLower(text)
+	switch {
+	case t == q:
+		return rankExact, true
+	case strings.HasPrefix(t, q):
+		return rankPrefix, true
+	}
+
+	i := strings.Index(t, q)
+	if i < 0 {
+		return 0, false
+	}
+	// Look for a later occurrence that starts a word
+	for ; i >= 0; i = nextIndex(t, q, i) {
+		if isWordStart(t, i) {
+			return rankWordPrefix, true
+		}
+	}
+	return rankSubstring, true
+}
+
+// nextIndex finds the next occurrence of q in t after position i, or -1
+func nextIndex(t, q string, i int) int {
+	j := strings.Index(t[i+1:], q)
+	if j < 0 {
+		return -1
+	}
+	return i + 1 + j
+}
+
+// isWordStart reports whether position i of t begins a word
+func isWordStart(t string, i int) bool {
+	switch t[i-1] {
+	case ' ', '-', '_', ',', '.', '(', '/', '&':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package catalog
+
+import (
+	"testing"
+
+	"groupie_tracker/models"
+)
+
+func suggestCatalog() *Catalog {
+	return New(
+		[]models.Artist{
+			{ID: 1, Name: "Queen", Members: []string{"Freddie Mercury", "Brian May"}, CreationDate: 1970, FirstAlbum: "14-12-1973"},
+			{ID: 2, Name: "Mamonas Assassinas", Members: []string{"Dinho"}, CreationDate: 1995, FirstAlbum: "23-06-1995"},
+			{ID: 3, Name: "Bon Jovi", Members: []string{"Jon Bon Jovi", "Tico Torres"}, CreationDate: 1983, FirstAlbum: "21-01-1984"},
+		},
+		[]models.Locations{
+			{ID: 1, Locations: []string{"london-uk", "new_york-usa", "guatemala_city-guatemala"}},
+			{ID: 2, Locations: []string{"sao_paulo-brazil"}},
+			{ID: 3, Locations: []string{"new_york-usa"}},
+		},
+		nil,
+		nil,
+	)
+}
+
+func TestSuggestPrefersPrefixMatches(t *testing.T) {
+	cat := suggestCatalog()
+
+	got := cat.Suggest("ma", 10)
+	want := []string{"Mamonas Assassinas", "Brian May", "Guatemala City, Guatemala"}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d suggestions, got %+v", len(want), got)
+	}
+	for i, text := range want {
+		if got[i].Text != text {
+			t.Errorf("Suggestion %d: expected %q, got %q", i, text, got[i].Text)
+		}
+	}
+}
+
+func TestSuggestTypesAndLinks(t *testing.T) {
+	cat := suggestCatalog()
+
+	tests := []struct {
+		query    string
+		text     string
+		kind     string
+		artistID int
+	}{
+		{"queen", "Queen", "artist", 1},
+		{"tico", "Tico Torres", "member", 3},
+		{"sao", "Sao Paulo, Brazil", "location", 2},
+		{"new_york", "New York, USA", "location", 0}, // shared by two artists
+		{"1983", "1983", "creation date", 3},
+		{"23-06", "23-06-1995", "first album", 2},
+	}
+
+	for _, tt := range tests {
+		got := cat.Suggest(tt.query, 10)
+		if len(got) == 0 {
+			t.Errorf("%q: expected a suggestion", tt.query)
+			continue
+		}
+		if got[0].Text != tt.text || got[0].Type != tt.kind || got[0].ArtistID != tt.artistID {
+			t.Errorf("%q: expected %q (%s, artist %d), got %+v", tt.query, tt.text, tt.kind, tt.artistID, got[0])
+		}
+	}
+}
+
+func TestSuggestLimitAndEmpty(t *testing.T) {
+	cat := suggestCatalog()
+
+	if got := cat.Suggest("  ", 10); got != nil {
+		t.Errorf("Expected no suggestions for a blank query, got %+v", got)
+	}
+	if got := cat.Suggest("o", 2); len(got) != 2 {
+		t.Errorf("Expected the limit to cap suggestions at 2, got %d", len(got))
+	}
+	if got := cat.Suggest("zzz", 10); len(got) != 0 {
+		t.Errorf("Expected no suggestions, got %+v", got)
+	}
+}
@@ -0,0 +1,107 @@
+package catalog
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Problem is a referential integrity problem in a catalog
+type Problem struct {
+	Resource string `json:"resource"` // artists, locations, dates or relation
+	ArtistID int    `json:"artistId"`
+	Message  string `json:"message"`
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: artist %d: %s", p.Resource, p.ArtistID, p.Message)
+}
+
+// ValidationError lists every problem that makes a catalog unusable
+type ValidationError struct {
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	const shown = 3
+	msgs := make([]string, 0, shown)
+	for i, p := range e.Problems {
+		if i == shown {
+			msgs = append(msgs, fmt.Sprintf("and %d more", len(e.Problems)-shown))
+			break
+		}
+		msgs = append(msgs, p.String())
+	}
+	return fmt.Sprintf("invalid catalog: %s", strings.Join(msgs, "; "))
+}
+
+// Validate checks that artist IDs are unique and positive, and that
+// every artist has locations, dates and relations and nothing else does.
+// It returns a *ValidationError listing all problems found.
+func (c *Catalog) Validate() error {
+	var problems []Problem
+
+	artistIDs := make(map[int]bool, len(c.Artists))
+	var list []int
+	for _, artist := range c.Artists {
+		list = append(list, artist.ID)
+		if artist.ID < 1 {
+			problems = append(problems, Problem{"artists", artist.ID, "ID must be positive"})
+		}
+		if strings.TrimSpace(artist.Name) == "" {
+			problems = append(problems, Problem{"artists", artist.ID, "name is empty"})
+		}
+		artistIDs[artist.ID] = true
+	}
+	problems = append(problems, duplicates("artists", list)...)
+
+	problems = append(problems, orphans("locations", artistIDs, keys(c.Locations))...)
+	problems = append(problems, orphans("dates", artistIDs, keys(c.Dates))...)
+	problems = append(problems, orphans("relation", artistIDs, keys(c.Relations))...)
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// orphans reports artists missing from a resource, and entries of the
+// resource that belong to no artist
+func orphans(resource string, artistIDs map[int]bool, entryIDs []int) []Problem {
+	var problems []Problem
+	entries := make(map[int]bool, len(entryIDs))
+	for _, id := range entryIDs {
+		entries[id] = true
+		if !artistIDs[id] {
+			problems = append(problems, Problem{resource, id, "entry has no matching artist"})
+		}
+	}
+	for _, id := range keys(artistIDs) {
+		if !entries[id] {
+			problems = append(problems, Problem{resource, id, "artist has no entry"})
+		}
+	}
+	return problems
+}
+
+// duplicates reports IDs listed more than once
+func duplicates(resource string, ids []int) []Problem {
+	var problems []Problem
+	seen := make(map[int]int, len(ids))
+	for _, id := range ids {
+		seen[id]++
+		if seen[id] == 2 {
+			problems = append(problems, Problem{resource, id, "ID is listed more than once"})
+		}
+	}
+	return problems
+}
+
+func keys[V any](m map[int]V) []int {
+	out := make([]int, 0, len(m))
+	for id := range m {
+		out = append(out, id)
+	}
+	sort.Ints(out)
+	return out
+}
@@ -0,0 +1,124 @@
+// Package chart draws simple bar charts as inline SVG, so pages can show
+// charts without any JavaScript.
+package chart
+
+import (
+	"fmt"
+	"html/template"
+	"strconv"
+	"strings"
+)
+
+// Bar is one bar of a chart
+type Bar struct {
+	Label string
+	Value int
+}
+
+// BarChart is a bar chart. Vertical charts suit ordered series such as
+// years; horizontal ones suit rankings with long labels.
+type BarChart struct {
+	Title      string // read out by screen readers
+	Bars       []Bar
+	Horizontal bool
+	// Format renders values and axis labels; defaults to strconv.Itoa
+	Format func(int) string
+}
+
+// Layout of the drawing, in SVG user units
+const (
+	width        = 600
+	plotHeight   = 200 // of vertical charts
+	rowHeight    = 26  // of horizontal charts
+	labelWidth   = 170 // left margin of horizontal charts, for the labels
+	valueWidth   = 50  // right margin of horizontal charts, for the values
+	bottomMargin = 40  // below vertical charts, for the labels
+	topMargin    = 20  // above vertical charts, for the values
+	barGap       = 0.2 // share of a slot left empty between bars
+)
+
+// SVG renders the chart. Empty charts render as an empty string.
+func (c BarChart) SVG() template.HTML {
+	if len(c.Bars) == 0 {
+		return ""
+	}
+	format := c.Format
+	if format == nil {
+		format = strconv.Itoa
+	}
+
+	// The longest bar spans the plot; a chart of zeros still divides by 1
+	top := 1
+	for _, bar := range c.Bars {
+		top = max(top, bar.Value)
+	}
+
+	var b strings.Builder
+	if c.Horizontal {
+		c.horizontal(&b, top, format)
+	} else {
+		c.vertical(&b, top, format)
+	}
+	return template.HTML(b.String())
+}
+
+func (c BarChart) open(b *strings.Builder, height int) {
+	fmt.Fprintf(b, `<svg class="chart" viewBox="0 0 %d %d" role="img" aria-label="%s" xmlns="http://www.w3.org/2000/svg">`,
+		width, height, esc(c.Title))
+	fmt.Fprintf(b, `<title>%s</title>`, esc(c.Title))
+}
+
+func (c BarChart) vertical(b *strings.Builder, top int, format func(int) string) {
+	height := topMargin + plotHeight + bottomMargin
+	c.open(b, height)
+
+	slot := float64(width) / float64(len(c.Bars))
+	barWidth := slot * (1 - barGap)
+	baseline := float64(topMargin + plotHeight)
+	// Skip labels when there is no room for them all
+	every := 1 + len(c.Bars)/20
+
+	fmt.Fprintf(b, `<line class="chart-axis" x1="0" y1="%.1f" x2="%d" y2="%.1f"/>`, baseline, width, baseline)
+	for i, bar := range c.Bars {
+		h := float64(bar.Value) / float64(top) * plotHeight
+		x := float64(i)*slot + (slot-barWidth)/2
+		mid := x + barWidth/2
+
+		fmt.Fprintf(b, `<g><title>%s: %s</title>`, esc(bar.Label), esc(format(bar.Value)))
+		fmt.Fprintf(b, `<rect class="chart-bar" x="%.1f" y="%.1f" width="%.1f" height="%.1f"/>`, x, baseline-h, barWidth, h)
+		if bar.Value > 0 {
+			fmt.Fprintf(b, `<text class="chart-value" x="%.1f" y="%.1f" text-anchor="middle">%s</text>`, mid, baseline-h-4, esc(format(bar.Value)))
+		}
+		if i%every == 0 {
+			fmt.Fprintf(b, `<text class="chart-label" x="%.1f" y="%.1f" text-anchor="middle">%s</text>`, mid, baseline+16, esc(bar.Label))
+		}
+		b.WriteString(`</g>`)
+	}
+	b.WriteString(`</svg>`)
+}
+
+func (c BarChart) horizontal(b *strings.Builder, top int, format func(int) string) {
+	height := len(c.Bars) * rowHeight
+	c.open(b, height)
+
+	plotWidth := float64(width - labelWidth - valueWidth)
+	barHeight := rowHeight * (1 - barGap)
+
+	for i, bar := range c.Bars {
+		w := float64(bar.Value) / float64(top) * plotWidth
+		y := float64(i*rowHeight) + (rowHeight-barHeight)/2
+		mid := y + barHeight/2
+
+		fmt.Fprintf(b, `<g><title>%s: %s</title>`, esc(bar.Label), esc(format(bar.Value)))
+		fmt.Fprintf(b, `<text class="chart-label" x="%d" y="%.1f" text-anchor="end" dominant-baseline="middle">%s</text>`, labelWidth-8, mid, esc(bar.Label))
+		fmt.Fprintf(b, `<rect class="chart-bar" x="%d" y="%.1f" width="%.1f" height="%.1f"/>`, labelWidth, y, w, barHeight)
+		fmt.Fprintf(b, `<text class="chart-value" x="%.1f" y="%.1f" dominant-baseline="middle">%s</text>`, float64(labelWidth)+w+6, mid, esc(format(bar.Value)))
+		b.WriteString(`</g>`)
+	}
+	b.WriteString(`</svg>`)
+}
+
+// esc escapes text for SVG content and attributes
+func esc(s string) string {
+	return template.HTMLEscapeString(s)
+}
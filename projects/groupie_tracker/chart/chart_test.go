@@ -0,0 +1,61 @@
+package chart
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVerticalBarChart(t *testing.T) {
+	svg := string(BarChart{
+		Title: "Concerts <per> year",
+		Bars:  []Bar{{"2019", 2}, {"2020", 4}, {"2021", 0}},
+	}.SVG())
+
+	if !strings.HasPrefix(svg, `<svg class="chart"`) || !strings.HasSuffix(svg, "</svg>") {
+		t.Fatalf("Expected a single svg element, got %s", svg)
+	}
+	if got := strings.Count(svg, `class="chart-bar"`); got != 3 {
+		t.Errorf("Expected 3 bars, got %d", got)
+	}
+	for _, want := range []string{
+		`role="img"`,
+		`<title>Concerts &lt;per&gt; year</title>`,
+		// The tallest bar fills the plot, the others are to scale
+		`height="200.0"`,
+		`height="100.0"`,
+		`<title>2021: 0</title>`,
+	} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("Expected chart to contain %q", want)
+		}
+	}
+	if strings.Contains(svg, "style=") {
+		t.Error("Expected no inline styles, which the content security policy blocks")
+	}
+}
+
+func TestHorizontalBarChart(t *testing.T) {
+	svg := string(BarChart{
+		Title:      "Top cities",
+		Bars:       []Bar{{"London, UK", 3}, {"Paris & Co", 1}},
+		Horizontal: true,
+		Format:     func(n int) string { return strings.Repeat("*", n) },
+	}.SVG())
+
+	for _, want := range []string{
+		`viewBox="0 0 600 52"`,
+		`>London, UK</text>`,
+		`>Paris &amp; Co</text>`,
+		`>***</text>`,
+	} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("Expected chart to contain %q", want)
+		}
+	}
+}
+
+func TestEmptyBarChart(t *testing.T) {
+	if svg := (BarChart{Title: "Nothing"}).SVG(); svg != "" {
+		t.Errorf("Expected no markup for an empty chart, got %s", svg)
+	}
+}
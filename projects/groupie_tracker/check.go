@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"groupie_tracker/catalog"
+	"io"
+	"text/tabwriter"
+)
+
+// Exit codes of the check command
+const (
+	checkOK       = 0
+	checkIssues   = 1
+	checkNoSource = 2
+)
+
+const checkUsage = `Usage: groupie_tracker check [flags]
+
+Loads the whole catalog and reports its inconsistencies. Exits with 0
+when the data is usable, 1 when it has errors and 2 when it cannot be
+loaded. Warnings such as starred dates do not fail the check unless
+-strict is given, so a cron job gating a snapshot deployment should run:
+
+    groupie_tracker check -strict
+
+Flags:
+`
+
+// runCheck implements "groupie_tracker check": it loads the whole catalog
+// and reports its inconsistencies. It exits non-zero when there are
+// errors, or warnings with -strict, so it can gate a deployment.
+func runCheck(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("check", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, checkUsage)
+		fs.PrintDefaults()
+	}
+	dataDir := fs.String("data", "", "directory of JSON files to check instead of the upstream API")
+	format := fs.String("format", "text", "output format: text or json")
+	strict := fs.Bool("strict", false, "also fail on warnings (use it when gating a deployment)")
+	if err := fs.Parse(args); err != nil {
+		return checkNoSource
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(stderr, "Unknown format %q, expected text or json\n", *format)
+		return checkNoSource
+	}
+
+	var source catalog.DataSource = catalog.HTTPSource{}
+	if *dataDir != "" {
+		source = catalog.DirSource{Dir: *dataDir}
+	}
+
+	report, err := catalog.Check(source)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error loading catalog: %v\n", err)
+		return checkNoSource
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			fmt.Fprintf(stderr, "Error encoding report: %v\n", err)
+		}
+	} else {
+		tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+		for _, issue := range report.Issues {
+			fmt.Fprintf(tw, "%s\t%s\tartist %d\t%s\n", issue.Severity, issue.Resource, issue.ArtistID, issue.Message)
+		}
+		tw.Flush()
+		fmt.Fprintf(stdout, "%d artists checked: %d errors, %d warnings\n",
+			report.Artists, report.Count(catalog.SeverityError), report.Count(catalog.SeverityWarning))
+	}
+
+	if report.Count(catalog.SeverityError) > 0 || (*strict && len(report.Issues) > 0) {
+		return checkIssues
+	}
+	return checkOK
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"groupie_tracker/catalog"
+)
+
+const dataset = "catalog/testdata/dataset"
+
+// check runs the check command with the given arguments
+func check(t *testing.T, args ...string) (stdout, stderr string, code int) {
+	t.Helper()
+	var out, errOut bytes.Buffer
+	code = runCheck(args, &out, &errOut)
+	return out.String(), errOut.String(), code
+}
+
+// brokenDataset copies the test dataset with an unparseable first album
+// date, which the tracker refuses to load
+func brokenDataset(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, name := range []string{"artists.json", "locations.json", "dates.json", "relation.json"} {
+		data, err := os.ReadFile(filepath.Join(dataset, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if name == "artists.json" {
+			data = bytes.Replace(data, []byte(`"14-03-2011"`), []byte(`"2011-03-14"`), 1)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestCheckExitCodes(t *testing.T) {
+	broken := brokenDataset(t)
+	tests := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{"warnings only", []string{"-data", dataset}, checkOK},
+		{"warnings with strict", []string{"-data", dataset, "-strict"}, checkIssues},
+		{"errors", []string{"-data", broken}, checkIssues},
+		{"missing directory", []string{"-data", filepath.Join(t.TempDir(), "missing")}, checkNoSource},
+		{"unknown format", []string{"-data", dataset, "-format", "xml"}, checkNoSource},
+		{"unknown flag", []string{"-data", dataset, "-nope"}, checkNoSource},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, stderr, code := check(t, tt.args...); code != tt.code {
+				t.Errorf("check %v: expected exit code %d, got %d (%s)", tt.args, tt.code, code, stderr)
+			}
+		})
+	}
+}
+
+func TestCheckText(t *testing.T) {
+	out, _, _ := check(t, "-data", brokenDataset(t))
+	for _, want := range []string{
+		`error    artists  artist 1  first album date "2011-03-14" cannot be parsed`,
+		"2 artists checked: 1 errors, 2 warnings\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected report to contain %q, got %q", want, out)
+		}
+	}
+}
+
+func TestCheckJSON(t *testing.T) {
+	out, _, code := check(t, "-data", dataset, "-format", "json")
+	if code != checkOK {
+		t.Fatalf("Expected exit code 0, got %d", code)
+	}
+	var report catalog.Report
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatalf("Expected a JSON report, got %q: %v", out, err)
+	}
+	if report.Artists != 2 || report.Count(catalog.SeverityWarning) != 2 {
+		t.Errorf("Expected 2 artists and 2 warnings, got %+v", report)
+	}
+}
+
+func TestCheckUsageMentionsStrict(t *testing.T) {
+	_, stderr, code := check(t, "-h")
+	if code != checkNoSource || !strings.Contains(stderr, "groupie_tracker check -strict") {
+		t.Errorf("Expected the usage to recommend -strict, got %d %q", code, stderr)
+	}
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"fmt"
+	"groupie_tracker/catalog"
+	"groupie_tracker/export"
+	"groupie_tracker/models"
+	"groupie_tracker/search"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// command runs a subcommand on the loaded catalog
+type command func(cat *catalog.Catalog, args []string, opts options) (output, error)
+
+var commands = map[string]command{
+	"list":      listCommand,
+	"show":      showCommand,
+	"concerts":  concertsCommand,
+	"search":    searchCommand,
+	"locations": locationsCommand,
+	"export":    exportCommand,
+}
+
+// dateLayout is how dates are printed in tables and CSV
+const dateLayout = "2006-01-02"
+
+// maxSearchResults limits the search command
+const maxSearchResults = 20
+
+// now is replaced in tests
+var now = time.Now
+
+func listCommand(cat *catalog.Catalog, args []string, opts options) (output, error) {
+	if len(args) != 0 {
+		return output{}, errUsage
+	}
+
+	out := output{
+		header: []string{"id", "name", "created", "first album", "members"},
+		value:  cat.Artists,
+	}
+	for _, artist := range cat.Artists {
+		out.rows = append(out.rows, []string{
+			strconv.Itoa(artist.ID),
+			artist.Name,
+			strconv.Itoa(artist.CreationDate),
+			artist.FirstAlbum,
+			strconv.Itoa(len(artist.Members)),
+		})
+	}
+	return out, nil
+}
+
+// ArtistDetails is the JSON output of the show command
+type ArtistDetails struct {
+	models.Artist
+	Concerts []catalog.Concert `json:"concerts"`
+}
+
+func showCommand(cat *catalog.Catalog, args []string, opts options) (output, error) {
+	if len(args) == 0 {
+		return output{}, errUsage
+	}
+	artist, err := findArtist(cat, joinArgs(args))
+	if err != nil {
+		return output{}, err
+	}
+
+	concerts := cat.Concerts(artist.ID)
+	details := ArtistDetails{Artist: artist, Concerts: concerts}
+	if details.Concerts == nil {
+		details.Concerts = []catalog.Concert{}
+	}
+
+	out := output{
+		header: []string{"field", "value"},
+		rows: [][]string{
+			{"id", strconv.Itoa(artist.ID)},
+			{"name", artist.Name},
+			{"created", strconv.Itoa(artist.CreationDate)},
+			{"first album", artist.FirstAlbum},
+			{"members", strings.Join(artist.Members, ", ")},
+			{"concerts", strconv.Itoa(len(concerts))},
+		},
+		value: details,
+	}
+	if upcoming := catalog.Upcoming(concerts, now()); len(upcoming) > 0 {
+		next := upcoming[0]
+		out.rows = append(out.rows, []string{"next concert", next.Date.Format(dateLayout) + " " + catalog.FormatLocation(next.Location)})
+	}
+	return out, nil
+}
+
+func concertsCommand(cat *catalog.Catalog, args []string, opts options) (output, error) {
+	if len(args) != 1 {
+		return output{}, errUsage
+	}
+	artist, err := findArtist(cat, args[0])
+	if err != nil {
+		return output{}, err
+	}
+
+	concerts := cat.Concerts(artist.ID)
+	if opts.upcoming {
+		concerts = catalog.Upcoming(concerts, now())
+	}
+	if concerts == nil {
+		concerts = []catalog.Concert{}
+	}
+
+	out := output{header: []string{"date", "location"}, value: concerts}
+	for _, concert := range concerts {
+		out.rows = append(out.rows, []string{concert.Date.Format(dateLayout), catalog.FormatLocation(concert.Location)})
+	}
+	return out, nil
+}
+
+// SearchHit is one result of the search command in JSON
+type SearchHit struct {
+	Artist string `json:"artist"`
+	search.Result
+}
+
+func searchCommand(cat *catalog.Catalog, args []string, opts options) (output, error) {
+	query := joinArgs(args)
+	if query == "" {
+		return output{}, errUsage
+	}
+
+	hits := []SearchHit{}
+	out := output{header: []string{"id", "artist", "score", "field", "match"}}
+	for _, result := range search.Build(cat).Search(query, maxSearchResults) {
+		artist, _ := cat.Artist(result.ArtistID)
+		hits = append(hits, SearchHit{Artist: artist.Name, Result: result})
+		out.rows = append(out.rows, []string{
+			strconv.Itoa(artist.ID),
+			artist.Name,
+			strconv.FormatFloat(result.Score, 'f', 2, 64),
+			result.Field,
+			result.Text,
+		})
+	}
+	out.value = hits
+	return out, nil
+}
+
+// Location is one concert location in the output of the locations command
+type Location struct {
+	Slug     string   `json:"slug"`
+	Name     string   `json:"name"`
+	Concerts int      `json:"concerts"`
+	Artists  []string `json:"artists"`
+}
+
+func locationsCommand(cat *catalog.Catalog, args []string, opts options) (output, error) {
+	if len(args) != 0 {
+		return output{}, errUsage
+	}
+
+	bySlug := make(map[string]*Location)
+	for _, artist := range cat.Artists {
+		for _, concert := range cat.Concerts(artist.ID) {
+			loc, ok := bySlug[concert.Location]
+			if !ok {
+				loc = &Location{Slug: concert.Location, Name: catalog.FormatLocation(concert.Location)}
+				bySlug[concert.Location] = loc
+			}
+			loc.Concerts++
+			if n := len(loc.Artists); n == 0 || loc.Artists[n-1] != artist.Name {
+				loc.Artists = append(loc.Artists, artist.Name)
+			}
+		}
+	}
+
+	// Busiest locations first
+	locations := make([]Location, 0, len(bySlug))
+	for _, loc := range bySlug {
+		locations = append(locations, *loc)
+	}
+	sort.Slice(locations, func(i, j int) bool {
+		if locations[i].Concerts != locations[j].Concerts {
+			return locations[i].Concerts > locations[j].Concerts
+		}
+		return locations[i].Name < locations[j].Name
+	})
+
+	out := output{header: []string{"location", "concerts", "artists"}, value: locations}
+	for _, loc := range locations {
+		out.rows = append(out.rows, []string{loc.Name, strconv.Itoa(loc.Concerts), strings.Join(loc.Artists, ", ")})
+	}
+	return out, nil
+}
+
+// exportCommand writes one of the spreadsheet tables, always as CSV
+func exportCommand(cat *catalog.Catalog, args []string, opts options) (output, error) {
+	if len(args) != 1 {
+		return output{}, errUsage
+	}
+	if opts.formatSet && opts.format != "csv" {
+		return output{}, fmt.Errorf("export only writes CSV, not %s", opts.format)
+	}
+	table := args[0]
+	if !slices.Contains(export.Tables, table) {
+		return output{}, fmt.Errorf("no table %q, expected one of %s", table, strings.Join(export.Tables, ", "))
+	}
+
+	return output{raw: func(w io.Writer) error {
+		return export.Write(w, cat, table, export.Options{BOM: opts.bom})
+	}}, nil
+}
+
+// findArtist looks an artist up by ID or by name, ignoring case
+func findArtist(cat *catalog.Catalog, ref string) (models.Artist, error) {
+	if id, err := strconv.Atoi(ref); err == nil {
+		if artist, ok := cat.Artist(id); ok {
+			return artist, nil
+		}
+		return models.Artist{}, fmt.Errorf("no artist with ID %d", id)
+	}
+	for _, artist := range cat.Artists {
+		if strings.EqualFold(artist.Name, ref) {
+			return artist, nil
+		}
+	}
+	return models.Artist{}, fmt.Errorf("no artist named %q, try: groupie search %s", ref, ref)
+}
@@ -0,0 +1,205 @@
+// Command groupie is a terminal client for the tracker data. It fetches
+// the catalog through the api package like the server does, and can work
+// offline from a catalog snapshot.
+//
+// Usage:
+//
+//	groupie [flags] <command> [args]
+//
+// Commands:
+//
+//	list                     all artists
+//	show <id|name>           one artist and its concerts
+//	concerts <id> -upcoming  the concerts of an artist
+//	search <query>           artists matching a query
+//	locations                every concert location
+//	export <table> -bom      a spreadsheet table as CSV: artists,
+//	                         members or concerts
+//
+// Flags may come before or after the command's arguments.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"groupie_tracker/catalog"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Exit codes
+const (
+	exitOK    = 0
+	exitError = 1 // the command failed, e.g. an unknown artist
+	exitUsage = 2
+)
+
+const usage = `Usage: groupie [flags] <command> [args]
+
+Commands:
+  list                     all artists
+  show <id|name>           one artist and its concerts
+  concerts <id> -upcoming  the concerts of an artist
+  search <query>           artists matching a query
+  locations                every concert location
+  export <table> -bom      a spreadsheet table as CSV: artists,
+                           members or concerts
+
+Flags:
+`
+
+// errUsage reports a command called with the wrong arguments
+var errUsage = errors.New("wrong arguments")
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// options are the flags shared by every command
+type options struct {
+	format    string
+	formatSet bool // -format was given, rather than defaulting to table
+	data      string
+	snapshot  string
+	offline   bool
+	upcoming  bool
+	bom       bool
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	var opts options
+	fs := flag.NewFlagSet("groupie", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&opts.format, "format", "table", "output format: table, json or csv")
+	fs.StringVar(&opts.data, "data", "", "directory of JSON files to read instead of the upstream API")
+	fs.StringVar(&opts.snapshot, "snapshot", defaultSnapshot(), "catalog snapshot for offline use (empty to disable)")
+	fs.BoolVar(&opts.offline, "offline", false, "only read the snapshot, never the network")
+	fs.BoolVar(&opts.upcoming, "upcoming", false, "concerts: only list concerts from today on")
+	fs.BoolVar(&opts.bom, "bom", false, "export: start with a UTF-8 byte order mark, for Excel")
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(positional) == 0 {
+		fs.Usage()
+		return exitUsage
+	}
+	fs.Visit(func(f *flag.Flag) {
+		opts.formatSet = opts.formatSet || f.Name == "format"
+	})
+	if opts.format != "table" && opts.format != "json" && opts.format != "csv" {
+		fmt.Fprintf(stderr, "Unknown format %q, expected table, json or csv\n", opts.format)
+		return exitUsage
+	}
+
+	command, ok := commands[positional[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "Unknown command %q\n", positional[0])
+		fs.Usage()
+		return exitUsage
+	}
+
+	cat, err := loadCatalog(opts, stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error loading catalog: %v\n", err)
+		return exitError
+	}
+
+	out, err := command(cat, positional[1:], opts)
+	if errors.Is(err, errUsage) {
+		fs.Usage()
+		return exitUsage
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitError
+	}
+
+	if err := out.write(stdout, opts.format); err != nil {
+		fmt.Fprintf(stderr, "Error writing output: %v\n", err)
+		return exitError
+	}
+	return exitOK
+}
+
+// parseInterspersed parses flags wherever they appear among the
+// positional arguments, so "concerts 3 -upcoming" works
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// defaultSnapshot keeps the snapshot in the user's cache directory
+func defaultSnapshot() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "groupie", "catalog.snapshot.json")
+}
+
+// loadCatalog reads the catalog from -data, or else from the upstream API,
+// saving it as the snapshot. The snapshot is used when offline or when
+// the API cannot be reached.
+func loadCatalog(opts options, stderr io.Writer) (*catalog.Catalog, error) {
+	if opts.data != "" {
+		return catalog.Load(catalog.DirSource{Dir: opts.data})
+	}
+	if opts.offline {
+		if opts.snapshot == "" {
+			return nil, errors.New("-offline needs a -snapshot file")
+		}
+		return catalog.LoadSnapshot(opts.snapshot)
+	}
+
+	cat, err := catalog.Load(catalog.HTTPSource{})
+	if err != nil {
+		if opts.snapshot == "" {
+			return nil, err
+		}
+		cached, snapErr := catalog.LoadSnapshot(opts.snapshot)
+		if snapErr != nil {
+			return nil, err
+		}
+		fmt.Fprintf(stderr, "Upstream API unavailable (%v), using the snapshot from %s\n",
+			err, cached.FetchedAt.Local().Format("2006-01-02 15:04"))
+		return cached, nil
+	}
+
+	if opts.snapshot != "" {
+		if err := saveSnapshot(opts.snapshot, cat); err != nil {
+			fmt.Fprintf(stderr, "Error saving snapshot: %v\n", err)
+		}
+	}
+	return cat, nil
+}
+
+func saveSnapshot(path string, cat *catalog.Catalog) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return catalog.SaveSnapshot(path, cat)
+}
+
+// joinArgs rebuilds a multi-word argument such as a search query
+func joinArgs(args []string) string {
+	return strings.TrimSpace(strings.Join(args, " "))
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"groupie_tracker/catalog"
+)
+
+const dataset = "../../catalog/testdata/dataset"
+
+// groupie runs the CLI on the test dataset
+func groupie(t *testing.T, args ...string) (stdout, stderr string, code int) {
+	t.Helper()
+	var out, errOut bytes.Buffer
+	code = run(append([]string{"-data", dataset, "-snapshot", ""}, args...), &out, &errOut)
+	return out.String(), errOut.String(), code
+}
+
+func TestList(t *testing.T) {
+	out, _, code := groupie(t, "list")
+	if code != exitOK {
+		t.Fatalf("Expected exit code 0, got %d", code)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected a header and 2 rows, got %q", out)
+	}
+	if !strings.HasPrefix(lines[0], "ID  NAME") || !strings.HasPrefix(lines[1], "1   Nile Sound    2009") {
+		t.Errorf("Expected aligned columns, got %q", out)
+	}
+}
+
+func TestShowByName(t *testing.T) {
+	out, _, code := groupie(t, "show", "desert", "RADIO", "-format", "csv")
+	if code != exitOK {
+		t.Fatalf("Expected exit code 0, got %d", code)
+	}
+	for _, want := range []string{"field,value\n", "id,2\n", `members,"Youssef Nabil, Karim Adel"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected CSV to contain %q, got %q", want, out)
+		}
+	}
+
+	_, errOut, code := groupie(t, "show", "nobody")
+	if code != exitError || !strings.Contains(errOut, `no artist named "nobody"`) {
+		t.Errorf("Expected an unknown artist to fail, got %d %q", code, errOut)
+	}
+}
+
+func TestConcertsUpcoming(t *testing.T) {
+	now = func() time.Time { return time.Date(2023, 7, 15, 12, 0, 0, 0, time.UTC) }
+	t.Cleanup(func() { now = time.Now })
+
+	out, _, _ := groupie(t, "concerts", "1", "--upcoming", "-format", "json")
+	var concerts []catalog.Concert
+	if err := json.Unmarshal([]byte(out), &concerts); err != nil {
+		t.Fatalf("Expected JSON output: %v", err)
+	}
+	if len(concerts) != 1 || concerts[0].Location != "alexandria-egypt" {
+		t.Errorf("Expected only the Alexandria concert, got %+v", concerts)
+	}
+
+	out, _, _ = groupie(t, "concerts", "1")
+	if !strings.Contains(out, "2023-07-12  Cairo, Egypt") {
+		t.Errorf("Expected all concerts without -upcoming, got %q", out)
+	}
+}
+
+func TestSearchAndLocations(t *testing.T) {
+	out, _, _ := groupie(t, "search", "karim", "adel")
+	if strings.Count(out, "Karim Adel") != 2 {
+		t.Errorf("Expected both bands of Karim Adel, got %q", out)
+	}
+
+	out, _, _ = groupie(t, "-format", "json", "locations")
+	var locations []Location
+	if err := json.Unmarshal([]byte(out), &locations); err != nil {
+		t.Fatalf("Expected JSON output: %v", err)
+	}
+	if len(locations) != 3 || locations[0].Name != "Alexandria, Egypt" {
+		t.Errorf("Unexpected locations %+v", locations)
+	}
+}
+
+func TestExport(t *testing.T) {
+	out, _, code := groupie(t, "export", "concerts", "-bom")
+	if code != exitOK {
+		t.Fatalf("Expected exit code 0, got %d", code)
+	}
+	want := "\uFEFFartist_id,artist,location,city,country,date\r\n" +
+		"1,Nile Sound,cairo-egypt,Cairo,Egypt,2023-07-12\r\n"
+	if !strings.HasPrefix(out, want) {
+		t.Errorf("Expected CSV, got %q", out)
+	}
+	if _, _, code := groupie(t, "export", "concerts", "-format", "csv"); code != exitOK {
+		t.Errorf("Expected -format csv to be accepted, got %d", code)
+	}
+	if out, errOut, code := groupie(t, "export", "concerts", "-format", "json"); code != exitError || out != "" || !strings.Contains(errOut, "only writes CSV") {
+		t.Errorf("Expected -format json to be rejected, got %d %q", code, errOut)
+	}
+
+	if _, errOut, code := groupie(t, "export", "venues"); code != exitError || !strings.Contains(errOut, "artists, members, concerts") {
+		t.Errorf("Expected an unknown table to fail, got %d %q", code, errOut)
+	}
+}
+
+func TestOfflineSnapshot(t *testing.T) {
+	cat, err := catalog.Load(catalog.DirSource{Dir: dataset})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	if err := catalog.SaveSnapshot(path, cat); err != nil {
+		t.Fatal(err)
+	}
+
+	var out, errOut bytes.Buffer
+	if code := run([]string{"-offline", "-snapshot", path, "list"}, &out, &errOut); code != exitOK {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, errOut.String())
+	}
+	if !strings.Contains(out.String(), "Desert Radio") {
+		t.Errorf("Expected the snapshot's artists, got %q", out.String())
+	}
+
+	if code := run([]string{"-offline", "-snapshot", filepath.Join(t.TempDir(), "missing.json"), "list"}, &out, &errOut); code != exitError {
+		t.Errorf("Expected a missing snapshot to fail, got %d", code)
+	}
+}
+
+func TestCSVNeutralizesFormulas(t *testing.T) {
+	cat, err := catalog.Load(catalog.DirSource{Dir: dataset})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cat.Artists[0].Name = `=HYPERLINK("https://example.com","Nile")`
+	cat.Artists[0].Members[0] = "@Omar"
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	if err := catalog.SaveSnapshot(path, cat); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, args := range [][]string{{"list"}, {"show", "1"}, {"search", "omar"}} {
+		var out, errOut bytes.Buffer
+		args = append([]string{"-offline", "-snapshot", path, "-format", "csv"}, args...)
+		if code := run(args, &out, &errOut); code != exitOK {
+			t.Fatalf("groupie %v: expected exit code 0, got %d: %s", args, code, errOut.String())
+		}
+		records, err := csv.NewReader(&out).ReadAll()
+		if err != nil {
+			t.Fatalf("groupie %v: expected valid CSV, got: %v", args, err)
+		}
+		neutralized := 0
+		for _, record := range records {
+			for _, cell := range record {
+				if strings.HasPrefix(cell, "=") || strings.HasPrefix(cell, "@") {
+					t.Errorf("groupie %v: expected %q not to start a formula", args, cell)
+				}
+				if strings.HasPrefix(cell, "'") {
+					neutralized++
+				}
+			}
+		}
+		if neutralized == 0 {
+			t.Errorf("groupie %v: expected neutralized cells, got %q", args, records)
+		}
+	}
+}
+
+func TestUsageErrors(t *testing.T) {
+	for _, args := range [][]string{{}, {"play"}, {"list", "extra"}, {"concerts"}, {"list", "-format", "xml"}} {
+		if _, _, code := groupie(t, args...); code != exitUsage {
+			t.Errorf("groupie %v: expected exit code %d, got %d", args, exitUsage, code)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"groupie_tracker/export"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// output is the result of a command: rows for tables and CSV, and the
+// value to encode for JSON. Commands with a fixed format set raw instead.
+type output struct {
+	header []string
+	rows   [][]string
+	value  any
+	raw    func(io.Writer) error
+}
+
+func (o output) write(w io.Writer, format string) error {
+	if o.raw != nil {
+		return o.raw(w)
+	}
+
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(o.value)
+	case "csv":
+		cw := csv.NewWriter(w)
+		if err := cw.Write(o.header); err != nil {
+			return err
+		}
+		for _, row := range o.rows {
+			cells := make([]string, len(row))
+			for i, cell := range row {
+				cells[i] = export.Neutralize(cell)
+			}
+			if err := cw.Write(cells); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	default:
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, strings.ToUpper(strings.Join(o.header, "\t")))
+		for _, row := range o.rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		return tw.Flush()
+	}
+}
@@ -0,0 +1,131 @@
+// Package events fans out server-sent events to connected browsers.
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"groupie_tracker/metrics"
+	"io"
+	"sync"
+)
+
+// Event is one server-sent event
+type Event struct {
+	ID   int64
+	Type string
+	Data []byte // JSON
+}
+
+// WriteTo writes the event in the text/event-stream format
+func (e Event) WriteTo(w io.Writer) (int64, error) {
+	n, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, e.Data)
+	return int64(n), err
+}
+
+// Subscription is a client's view of the hub. Events is closed when the
+// client unsubscribes or falls too far behind.
+type Subscription struct {
+	Events <-chan Event
+	events chan Event
+}
+
+// Hub broadcasts events to every subscriber. Publish never blocks: a
+// subscriber whose buffer is full is dropped, and is expected to
+// reconnect and catch up from the hub's history.
+type Hub struct {
+	mu      sync.Mutex
+	subs    map[*Subscription]bool
+	history []Event // the most recent events, for reconnecting clients
+	nextID  int64
+	buffer  int
+}
+
+// historySize is how many past events a reconnecting client can replay
+const historySize = 32
+
+// NewHub creates a hub giving each subscriber room for buffer events
+func NewHub(buffer int) *Hub {
+	return &Hub{subs: make(map[*Subscription]bool), nextID: 1, buffer: buffer}
+}
+
+// Subscribe registers a new client. Events published after lastID, the
+// ID of the last event the client saw (0 for none), are replayed first
+// if they are still in the history.
+func (h *Hub) Subscribe(lastID int64) *Subscription {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var missed []Event
+	if lastID > 0 {
+		for _, e := range h.history {
+			if e.ID > lastID {
+				missed = append(missed, e)
+			}
+		}
+	}
+
+	ch := make(chan Event, h.buffer+len(missed))
+	for _, e := range missed {
+		ch <- e
+	}
+	s := &Subscription{Events: ch, events: ch}
+	h.subs[s] = true
+	return s
+}
+
+// Unsubscribe removes a client and closes its channel. It is safe to call
+// for a subscription the hub already dropped.
+func (h *Hub) Unsubscribe(s *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.subs[s] {
+		delete(h.subs, s)
+		close(s.events)
+	}
+}
+
+// Publish sends an event with v encoded as JSON to every subscriber
+func (h *Hub) Publish(eventType string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	e := Event{ID: h.nextID, Type: eventType, Data: data}
+	h.nextID++
+	h.history = append(h.history, e)
+	if len(h.history) > historySize {
+		h.history = h.history[len(h.history)-historySize:]
+	}
+
+	for s := range h.subs {
+		select {
+		case s.events <- e:
+		default:
+			// Too slow: drop it rather than hold up everyone else
+			delete(h.subs, s)
+			close(s.events)
+		}
+	}
+	return nil
+}
+
+// Len returns the number of connected subscribers
+func (h *Hub) Len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subs)
+}
+
+// ExportMetrics publishes the number of connected subscribers
+func (h *Hub) ExportMetrics() {
+	metrics.NewGaugeFunc(
+		"groupie_events_subscribers",
+		"Browsers connected to the server-sent events stream.",
+		func() float64 { return float64(h.Len()) },
+	)
+}
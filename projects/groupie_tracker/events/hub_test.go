@@ -0,0 +1,106 @@
+package events
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func receive(t *testing.T, s *Subscription) Event {
+	t.Helper()
+	select {
+	case e, ok := <-s.Events:
+		if !ok {
+			t.Fatal("Expected an event, subscription was closed")
+		}
+		return e
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for an event")
+	}
+	return Event{}
+}
+
+func TestPublishFansOut(t *testing.T) {
+	h := NewHub(4)
+	a, b := h.Subscribe(0), h.Subscribe(0)
+
+	if err := h.Publish("refresh", map[string]int{"changes": 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range []*Subscription{a, b} {
+		e := receive(t, s)
+		if e.ID != 1 || e.Type != "refresh" || string(e.Data) != `{"changes":2}` {
+			t.Errorf("Expected refresh event 1, got %+v", e)
+		}
+	}
+}
+
+func TestSlowSubscriberIsDropped(t *testing.T) {
+	h := NewHub(1)
+	slow, fast := h.Subscribe(0), h.Subscribe(0)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 3; i++ {
+			h.Publish("refresh", i)
+			<-fast.Events
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Publish blocked on a slow subscriber")
+	}
+
+	receive(t, slow) // the one event that fit
+	if _, ok := <-slow.Events; ok {
+		t.Error("Expected the slow subscriber to be closed")
+	}
+	if h.Len() != 1 {
+		t.Errorf("Expected 1 subscriber left, got %d", h.Len())
+	}
+
+	h.Unsubscribe(slow) // already dropped, must not panic
+}
+
+func TestUnsubscribe(t *testing.T) {
+	h := NewHub(4)
+	s := h.Subscribe(0)
+	h.Unsubscribe(s)
+
+	if _, ok := <-s.Events; ok {
+		t.Error("Expected the subscription to be closed")
+	}
+	if h.Len() != 0 {
+		t.Errorf("Expected no subscribers, got %d", h.Len())
+	}
+	h.Publish("refresh", nil)
+}
+
+func TestSubscribeReplaysMissedEvents(t *testing.T) {
+	h := NewHub(4)
+	for i := 0; i < 3; i++ {
+		h.Publish("refresh", i)
+	}
+
+	s := h.Subscribe(1)
+	if e := receive(t, s); e.ID != 2 {
+		t.Errorf("Expected replay to start at event 2, got %d", e.ID)
+	}
+	if e := receive(t, s); e.ID != 3 {
+		t.Errorf("Expected event 3 next, got %d", e.ID)
+	}
+}
+
+func TestEventWriteTo(t *testing.T) {
+	var buf bytes.Buffer
+	Event{ID: 7, Type: "change", Data: []byte(`{"a":1}`)}.WriteTo(&buf)
+
+	want := "id: 7\nevent: change\ndata: {\"a\":1}\n\n"
+	if buf.String() != want {
+		t.Errorf("Expected %q, got %q", want, buf.String())
+	}
+}
@@ -0,0 +1,132 @@
+// Package export flattens the catalog into CSV tables (RFC 4180) for
+// spreadsheets.
+package export
+
+import (
+	"encoding/csv"
+	"errors"
+	"groupie_tracker/catalog"
+	"groupie_tracker/models"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Tables lists the tables that can be exported, in a stable order
+var Tables = []string{"artists", "members", "concerts"}
+
+// ErrUnknownTable is returned for a table not in Tables
+var ErrUnknownTable = errors.New("unknown export table")
+
+// bom is the UTF-8 byte order mark Excel needs to detect the encoding
+const bom = "\uFEFF"
+
+// dateLayout is ISO 8601, which spreadsheets read as a date in any locale
+const dateLayout = "2006-01-02"
+
+// formulaStarts are the characters that make spreadsheets evaluate a cell
+// as a formula
+const formulaStarts = "=+-@\t\r"
+
+// Options tune the output for the program reading it
+type Options struct {
+	BOM bool // start with a UTF-8 byte order mark, for Excel
+}
+
+// Write writes one table as CSV. Columns never change order; new columns
+// are only ever appended. Rows are ordered by artist ID.
+func Write(w io.Writer, cat *catalog.Catalog, table string, opts Options) error {
+	var rows [][]string
+	switch table {
+	case "artists":
+		rows = artists(cat)
+	case "members":
+		rows = members(cat)
+	case "concerts":
+		rows = concerts(cat)
+	default:
+		return ErrUnknownTable
+	}
+
+	for _, row := range rows[1:] {
+		for i, cell := range row {
+			row[i] = Neutralize(cell)
+		}
+	}
+
+	if opts.BOM {
+		if _, err := io.WriteString(w, bom); err != nil {
+			return err
+		}
+	}
+	cw := csv.NewWriter(w)
+	cw.UseCRLF = true // RFC 4180 line endings
+	cw.WriteAll(rows)
+	return cw.Error()
+}
+
+// Neutralize keeps a cell from running as a formula when a CSV file is
+// opened in a spreadsheet (CSV injection): upstream names are not ours to
+// trust. The leading quote makes the cell text, and is not displayed.
+// Every CSV the tracker writes must pass its cells through it.
+func Neutralize(cell string) string {
+	if cell != "" && strings.ContainsRune(formulaStarts, rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
+
+func artists(cat *catalog.Catalog) [][]string {
+	rows := [][]string{{"artist_id", "name", "creation_date", "first_album", "member_count", "image"}}
+	for _, artist := range sortedArtists(cat) {
+		firstAlbum := artist.FirstAlbum
+		if date, err := catalog.ParseDate(firstAlbum); err == nil {
+			firstAlbum = date.Format(dateLayout)
+		}
+		rows = append(rows, []string{
+			strconv.Itoa(artist.ID),
+			artist.Name,
+			strconv.Itoa(artist.CreationDate),
+			firstAlbum,
+			strconv.Itoa(len(artist.Members)),
+			artist.Image,
+		})
+	}
+	return rows
+}
+
+func members(cat *catalog.Catalog) [][]string {
+	rows := [][]string{{"artist_id", "artist", "member", "member_slug"}}
+	for _, artist := range sortedArtists(cat) {
+		for _, member := range artist.Members {
+			rows = append(rows, []string{strconv.Itoa(artist.ID), artist.Name, member, catalog.Slug(member)})
+		}
+	}
+	return rows
+}
+
+func concerts(cat *catalog.Catalog) [][]string {
+	rows := [][]string{{"artist_id", "artist", "location", "city", "country", "date"}}
+	for _, artist := range sortedArtists(cat) {
+		for _, concert := range cat.Concerts(artist.ID) {
+			city, country := catalog.SplitLocation(concert.Location)
+			rows = append(rows, []string{
+				strconv.Itoa(artist.ID),
+				artist.Name,
+				concert.Location,
+				catalog.FormatLocation(city),
+				catalog.FormatCountry(country),
+				concert.Date.Format(dateLayout),
+			})
+		}
+	}
+	return rows
+}
+
+// sortedArtists returns the artists by ID without reordering the catalog
+func sortedArtists(cat *catalog.Catalog) []models.Artist {
+	artists := append([]models.Artist(nil), cat.Artists...)
+	sort.Slice(artists, func(i, j int) bool { return artists[i].ID < artists[j].ID })
+	return artists
+}
@@ -0,0 +1,88 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"groupie_tracker/catalog"
+	"groupie_tracker/models"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+// testCatalog has names that need quoting or look like formulas, listed
+// out of ID order
+func testCatalog() *catalog.Catalog {
+	return catalog.New(
+		[]models.Artist{
+			{ID: 2, Name: `"Weird" Al, Band`, Image: "https://example.com/al.jpeg", Members: []string{"Al \"Weird\" Y.", "Line\nBreak"}, CreationDate: 1976, FirstAlbum: "26-04-1983"},
+			{ID: 1, Name: "Björk", Image: "https://example.com/bjork.jpeg", Members: []string{"Björk Guðmundsdóttir"}, CreationDate: 1977, FirstAlbum: "not a date"},
+			{ID: 3, Name: `=HYPERLINK("https://example.com","Click")`, Image: "https://example.com/x.jpeg", Members: []string{"+Plus", "-Minus", "@At"}, CreationDate: 2001, FirstAlbum: "01-01-2001"},
+		},
+		nil,
+		nil,
+		[]models.Relation{
+			{ID: 1, DatesLocations: map[string][]string{"reykjavik-iceland": {"01-06-2020"}, "new_york-usa": {"20-05-2020"}}},
+			{ID: 2, DatesLocations: map[string][]string{"los_angeles-usa": {"02-02-2019", "*03-02-2019"}}},
+		},
+	)
+}
+
+func TestWriteGolden(t *testing.T) {
+	for _, table := range Tables {
+		t.Run(table, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Write(&buf, testCatalog(), table, Options{}); err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			golden := filepath.Join("testdata", table+".csv")
+			if *update {
+				os.WriteFile(golden, buf.Bytes(), 0o644)
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("Could not read golden file: %v", err)
+			}
+			if !bytes.Equal(buf.Bytes(), want) {
+				t.Errorf("Table does not match %s (run with -update to refresh)\ngot:\n%s", golden, buf.String())
+			}
+
+			// Whatever the quoting, it must read back as a rectangular table
+			records, err := csv.NewReader(&buf).ReadAll()
+			if err != nil {
+				t.Fatalf("Expected valid CSV, got: %v", err)
+			}
+			for _, record := range records[1:] {
+				if len(record) != len(records[0]) {
+					t.Errorf("Expected %d fields, got %q", len(records[0]), record)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteBOM(t *testing.T) {
+	var buf bytes.Buffer
+	Write(&buf, testCatalog(), "artists", Options{BOM: true})
+	if !strings.HasPrefix(buf.String(), "\uFEFFartist_id,") {
+		t.Errorf("Expected a byte order mark before the header, got %q", buf.String()[:20])
+	}
+}
+
+func TestWriteUnknownTable(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, testCatalog(), "venues", Options{}); !errors.Is(err, ErrUnknownTable) {
+		t.Errorf("Expected ErrUnknownTable, got %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Expected no output for an unknown table, got %q", buf.String())
+	}
+}
@@ -0,0 +1,65 @@
+package favorites
+
+import (
+	"slices"
+	"sync"
+)
+
+// Store keeps the starred artist IDs of each session
+type Store interface {
+	// List returns the starred artist IDs of a session in the order they were added
+	List(session string) ([]int, error)
+	Add(session string, artistID int) error
+	Remove(session string, artistID int) error
+}
+
+// MemoryStore is a Store that forgets everything on restart
+type MemoryStore struct {
+	mu   sync.RWMutex
+	favs map[string][]int
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{favs: make(map[string][]int)}
+}
+
+func (s *MemoryStore) List(session string) ([]int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Clone(s.favs[session]), nil
+}
+
+func (s *MemoryStore) Add(session string, artistID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	add(s.favs, session, artistID)
+	return nil
+}
+
+func (s *MemoryStore) Remove(session string, artistID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	remove(s.favs, session, artistID)
+	return nil
+}
+
+func add(favs map[string][]int, session string, artistID int) bool {
+	if slices.Contains(favs[session], artistID) {
+		return false
+	}
+	favs[session] = append(favs[session], artistID)
+	return true
+}
+
+func remove(favs map[string][]int, session string, artistID int) bool {
+	i := slices.Index(favs[session], artistID)
+	if i < 0 {
+		return false
+	}
+	favs[session] = slices.Delete(favs[session], i, i+1)
+	if len(favs[session]) == 0 {
+		delete(favs, session)
+	}
+	return true
+}
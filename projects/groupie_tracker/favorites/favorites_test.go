@@ -0,0 +1,146 @@
+package favorites
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// testStore runs the behaviour every Store must have
+func testStore(t *testing.T, s Store) {
+	t.Helper()
+
+	for _, id := range []int{3, 1, 3, 2} {
+		if err := s.Add("alice", id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Remove("alice", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Remove("alice", 99); err != nil {
+		t.Errorf("Expected removing a missing artist to be a no-op, got %v", err)
+	}
+
+	got, _ := s.List("alice")
+	if !slices.Equal(got, []int{3, 2}) {
+		t.Errorf("Expected [3 2] in the order added, without duplicates, got %v", got)
+	}
+	if got, _ := s.List("bob"); len(got) != 0 {
+		t.Errorf("Expected sessions to be separate, got %v", got)
+	}
+
+	// List returns a copy
+	got[0] = 42
+	if again, _ := s.List("alice"); again[0] != 3 {
+		t.Error("Expected List not to expose the store's slice")
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "favorites.json")
+	s, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStore(t, s)
+
+	// A new store over the same file sees the same favorites
+	reopened, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := reopened.List("alice"); !slices.Equal(got, []int{3, 2}) {
+		t.Errorf("Expected [3 2] after reopening, got %v", got)
+	}
+
+	// No temporary files are left behind
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("Expected only the favorites file, got %d entries", len(entries))
+	}
+}
+
+func TestFileStoreCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "favorites.json")
+	os.WriteFile(path, []byte("{not json"), 0o644)
+
+	if _, err := NewFileStore(path); err == nil {
+		t.Error("Expected an error for a corrupt file")
+	}
+}
+
+func TestFileStoreKeepsMemoryInStepWithDisk(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "favorites.json")
+	s, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Add("a", 1); err != nil {
+		t.Fatal(err)
+	}
+	s.Add("a", 2)
+
+	// Make every write fail from now on
+	s.path = filepath.Join(dir, "missing", "favorites.json")
+
+	if err := s.Add("a", 3); err == nil {
+		t.Error("Expected adding to fail when the file cannot be written")
+	}
+	if err := s.Add("b", 1); err == nil {
+		t.Error("Expected adding to a new session to fail when the file cannot be written")
+	}
+	if err := s.Remove("a", 1); err == nil {
+		t.Error("Expected removing to fail when the file cannot be written")
+	}
+	if ids, _ := s.List("a"); !slices.Equal(ids, []int{1, 2}) {
+		t.Errorf("Expected the failed changes to be rolled back, got %v", ids)
+	}
+	if ids, _ := s.List("b"); len(ids) != 0 {
+		t.Errorf("Expected no favorites for the new session, got %v", ids)
+	}
+
+	reopened, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids, _ := reopened.List("a"); !slices.Equal(ids, []int{1, 2}) {
+		t.Errorf("Expected the file to match memory, got %v", ids)
+	}
+}
+
+func TestFileStoreConcurrentWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "favorites.json")
+	s, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 1; i <= 20; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			if err := s.Add("alice", id); err != nil {
+				t.Error(err)
+			}
+			s.List("alice")
+		}(i)
+	}
+	wg.Wait()
+
+	reopened, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := reopened.List("alice"); len(got) != 20 {
+		t.Errorf("Expected all 20 concurrent additions to be saved, got %v", got)
+	}
+}
@@ -0,0 +1,99 @@
+package favorites
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+// FileStore is a Store persisted as a JSON file. The whole file is
+// rewritten on every change, which is fine for a campus-sized audience.
+type FileStore struct {
+	mu   sync.RWMutex
+	path string
+	favs map[string][]int
+}
+
+// NewFileStore opens the store at path, starting empty if it does not exist yet
+func NewFileStore(path string) (*FileStore, error) {
+	s := &FileStore{path: path, favs: make(map[string][]int)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &s.favs); err != nil {
+		return nil, fmt.Errorf("failed to decode favorites file %s: %w", path, err)
+	}
+	return s, nil
+}
+
+func (s *FileStore) List(session string) ([]int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Clone(s.favs[session]), nil
+}
+
+func (s *FileStore) Add(session string, artistID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.update(session, func() bool { return add(s.favs, session, artistID) })
+}
+
+func (s *FileStore) Remove(session string, artistID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.update(session, func() bool { return remove(s.favs, session, artistID) })
+}
+
+// update applies change to the session's favorites and saves them. If the
+// file cannot be written the session's list is put back, so a change is
+// never kept in memory only to be lost on restart. The caller must hold s.mu.
+func (s *FileStore) update(session string, change func() bool) error {
+	prev, had := s.favs[session]
+	prev = slices.Clone(prev) // remove edits the list in place
+
+	if !change() {
+		return nil
+	}
+	if err := s.save(); err != nil {
+		if had {
+			s.favs[session] = prev
+		} else {
+			delete(s.favs, session)
+		}
+		return err
+	}
+	return nil
+}
+
+// save writes the file atomically. The caller must hold s.mu.
+func (s *FileStore) save() error {
+	data, err := json.Marshal(s.favs)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
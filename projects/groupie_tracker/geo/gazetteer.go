@@ -0,0 +1,53 @@
+package geo
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//go:embed gazetteer.csv
+var gazetteerCSV string
+
+// gazetteer maps upstream location slugs to coordinates
+var gazetteer = sync.OnceValue(func() map[string]Point {
+	places, err := parseGazetteer(gazetteerCSV)
+	if err != nil {
+		panic(err) // the embedded file is checked by the tests
+	}
+	return places
+})
+
+// Lookup returns the coordinates of a location slug such as "london-uk"
+func Lookup(slug string) (Point, bool) {
+	p, ok := gazetteer()[slug]
+	return p, ok
+}
+
+func parseGazetteer(data string) (map[string]Point, error) {
+	r := csv.NewReader(strings.NewReader(data))
+	r.Comment = '#'
+	r.FieldsPerRecord = 3
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("gazetteer: %w", err)
+	}
+
+	places := make(map[string]Point, len(records))
+	for i, record := range records[1:] { // after the header
+		lat, latErr := strconv.ParseFloat(record[1], 64)
+		lon, lonErr := strconv.ParseFloat(record[2], 64)
+		p := Point{Lat: lat, Lon: lon}
+		if latErr != nil || lonErr != nil || !p.Valid() {
+			return nil, fmt.Errorf("gazetteer: invalid coordinates for %q on record %d", record[0], i+2)
+		}
+		if _, dup := places[record[0]]; dup {
+			return nil, fmt.Errorf("gazetteer: %q is listed twice", record[0])
+		}
+		places[record[0]] = p
+	}
+	return places, nil
+}
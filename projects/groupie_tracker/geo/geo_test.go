@@ -0,0 +1,167 @@
+package geo
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"groupie_tracker/catalog"
+	"groupie_tracker/models"
+)
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b Point
+		want float64 // km
+		tol  float64
+	}{
+		{"same point", Point{51.5, -0.1}, Point{51.5, -0.1}, 0, 1e-9},
+		{"one degree of the equator", Point{0, 0}, Point{0, 1}, EarthRadiusKm * math.Pi / 180, 1e-6},
+		{"antipodes", Point{0, 0}, Point{0, 180}, EarthRadiusKm * math.Pi, 1e-6},
+		{"pole to pole", Point{90, 0}, Point{-90, 0}, EarthRadiusKm * math.Pi, 1e-6},
+		{"across the antimeridian", Point{0, 179.5}, Point{0, -179.5}, EarthRadiusKm * math.Pi / 180, 1e-6},
+		{"London to Paris", Point{51.5074, -0.1278}, Point{48.8566, 2.3522}, 343.5, 1},
+		{"New York to Los Angeles", Point{40.7128, -74.0060}, Point{34.0522, -118.2437}, 3935.7, 5},
+	}
+	for _, tt := range tests {
+		if got := Distance(tt.a, tt.b); math.Abs(got-tt.want) > tt.tol {
+			t.Errorf("%s: Distance = %.3f km, expected %.3f", tt.name, got, tt.want)
+		}
+		if got, back := Distance(tt.a, tt.b), Distance(tt.b, tt.a); math.Abs(got-back) > 1e-9 {
+			t.Errorf("%s: expected a symmetric distance, got %v and %v", tt.name, got, back)
+		}
+	}
+}
+
+func TestGazetteer(t *testing.T) {
+	if _, err := parseGazetteer(gazetteerCSV); err != nil {
+		t.Fatalf("Expected the embedded gazetteer to be valid: %v", err)
+	}
+	if p, ok := Lookup("london-uk"); !ok || math.Abs(p.Lat-51.51) > 0.01 {
+		t.Errorf("Expected London, got %v %v", p, ok)
+	}
+	if _, ok := Lookup("atlantis-ocean"); ok {
+		t.Error("Expected no coordinates for an unknown place")
+	}
+
+	for _, bad := range []string{
+		"slug,lat,lon\nx-y,91,0\n",
+		"slug,lat,lon\nx-y,0,abc\n",
+		"slug,lat,lon\nx-y,1,1\nx-y,2,2\n",
+		"slug,lat,lon\nx-y,1\n",
+	} {
+		if _, err := parseGazetteer(bad); err == nil {
+			t.Errorf("Expected an error for %q", bad)
+		}
+	}
+}
+
+// The tree must find exactly what a full scan finds
+func TestKDTreeMatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	random := func() Point {
+		return Point{Lat: math.Asin(2*rng.Float64()-1) * 180 / math.Pi, Lon: rng.Float64()*360 - 180}
+	}
+
+	points := make([]Point, 500)
+	vecs := make([]vec, len(points))
+	for i := range points {
+		points[i] = random()
+		vecs[i] = toVec(points[i])
+	}
+	tree := newKDTree(vecs)
+
+	for q := 0; q < 200; q++ {
+		center, km := random(), rng.Float64()*3000
+		var got, want []int
+		tree.within(toVec(center), chord(km), func(i int) {
+			if Distance(center, points[i]) <= km {
+				got = append(got, i)
+			}
+		})
+		for i, p := range points {
+			if Distance(center, p) <= km {
+				want = append(want, i)
+			}
+		}
+		sort.Ints(got)
+		if !equalInts(got, want) {
+			t.Fatalf("Query %v within %.0f km: tree found %v, scan found %v", center, km, got, want)
+		}
+	}
+}
+
+func TestKDTreeAcrossTheAntimeridian(t *testing.T) {
+	tree := newKDTree([]vec{toVec(Point{-17.5, 179.9}), toVec(Point{-17.5, 100})})
+	var found []int
+	tree.within(toVec(Point{-17.5, -179.9}), chord(50), func(i int) { found = append(found, i) })
+	if !equalInts(found, []int{0}) {
+		t.Errorf("Expected the point 21 km away across the antimeridian, got %v", found)
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func testCatalog() *catalog.Catalog {
+	return catalog.New(
+		[]models.Artist{{ID: 1, Name: "Queen"}, {ID: 2, Name: "Pink Floyd"}},
+		nil, nil,
+		[]models.Relation{
+			{ID: 1, DatesLocations: map[string][]string{"london-uk": {"01-06-2020", "01-01-2020"}, "new_york-usa": {"01-03-2020"}}},
+			{ID: 2, DatesLocations: map[string][]string{"paris-france": {"01-02-2020"}, "atlantis-ocean": {"01-02-2020"}}},
+		},
+	)
+}
+
+func TestNear(t *testing.T) {
+	ix := Build(testCatalog())
+	london := Point{51.5074, -0.1278}
+
+	matches := ix.Near(london, 500, time.Time{}, time.Time{})
+	var got []string
+	for _, m := range matches {
+		got = append(got, m.Artist.Name+"@"+m.Place.Slug+"@"+m.Date.Format(time.DateOnly))
+	}
+	want := "Queen@london-uk@2020-01-01 Queen@london-uk@2020-06-01 Pink Floyd@paris-france@2020-02-01"
+	if strings.Join(got, " ") != want {
+		t.Errorf("Near London = %v, expected %s", got, want)
+	}
+	if matches[2].Place.Name != "Paris, France" || math.Abs(matches[2].DistanceKm-343) > 2 {
+		t.Errorf("Expected Paris about 343 km away, got %+v", matches[2])
+	}
+
+	// The date window is inclusive at both ends
+	from := time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	if matches := ix.Near(london, 500, from, to); len(matches) != 2 || matches[0].Date != to {
+		t.Errorf("Expected the June and February concerts, got %+v", matches)
+	}
+
+	if matches := ix.Near(london, 10, time.Time{}, time.Time{}); len(matches) != 2 {
+		t.Errorf("Expected only the London concerts within 10 km, got %+v", matches)
+	}
+	if matches := ix.Near(Point{0, 0}, 100, time.Time{}, time.Time{}); matches == nil || len(matches) != 0 {
+		t.Errorf("Expected an empty list in the middle of the ocean, got %#v", matches)
+	}
+	if matches := ix.Near(london, 25000, time.Time{}, time.Time{}); len(matches) != 4 {
+		t.Errorf("Expected every resolved concert within half the globe, got %d", len(matches))
+	}
+
+	if got := ix.Unresolved(); len(got) != 1 || got[0] != "atlantis-ocean" {
+		t.Errorf("Expected atlantis-ocean to be unresolved, got %v", got)
+	}
+}
@@ -0,0 +1,109 @@
+package geo
+
+import (
+	"groupie_tracker/catalog"
+	"sort"
+	"time"
+)
+
+// Place is a concert location on the map
+type Place struct {
+	Slug  string `json:"slug"`
+	Name  string `json:"name"`
+	Point Point  `json:"point"`
+}
+
+// Match is a concert found near a point
+type Match struct {
+	Artist     catalog.ArtistRef `json:"artist"`
+	Place      Place             `json:"place"`
+	Date       time.Time         `json:"date"`
+	DistanceKm float64           `json:"distanceKm"`
+}
+
+// Index finds the concerts of a catalog near a point
+type Index struct {
+	places     []Place
+	concerts   [][]Match // per place, by date
+	tree       *kdTree   // over places
+	unresolved []string
+}
+
+// Build indexes every concert of the catalog whose location is in the
+// gazetteer. The others are listed by Unresolved.
+func Build(cat *catalog.Catalog) *Index {
+	ix := &Index{}
+	bySlug := make(map[string]int)
+	missing := make(map[string]bool)
+
+	for _, artist := range cat.Artists {
+		ref := catalog.ArtistRef{ID: artist.ID, Name: artist.Name}
+		for _, concert := range cat.Concerts(artist.ID) {
+			i, ok := bySlug[concert.Location]
+			if !ok {
+				point, found := Lookup(concert.Location)
+				if !found {
+					missing[concert.Location] = true
+					continue
+				}
+				i = len(ix.places)
+				bySlug[concert.Location] = i
+				ix.places = append(ix.places, Place{Slug: concert.Location, Name: catalog.FormatLocation(concert.Location), Point: point})
+				ix.concerts = append(ix.concerts, nil)
+			}
+			ix.concerts[i] = append(ix.concerts[i], Match{Artist: ref, Place: ix.places[i], Date: concert.Date})
+		}
+	}
+
+	points := make([]vec, len(ix.places))
+	for i, place := range ix.places {
+		points[i] = toVec(place.Point)
+		sort.SliceStable(ix.concerts[i], func(a, b int) bool {
+			return ix.concerts[i][a].Date.Before(ix.concerts[i][b].Date)
+		})
+	}
+	ix.tree = newKDTree(points)
+
+	for slug := range missing {
+		ix.unresolved = append(ix.unresolved, slug)
+	}
+	sort.Strings(ix.unresolved)
+	return ix
+}
+
+// Unresolved lists the concert locations missing from the gazetteer
+func (ix *Index) Unresolved() []string {
+	return ix.unresolved
+}
+
+// Near returns the concerts within km of center, nearest first, then by
+// date. Zero from or to times leave that end of the date window open;
+// both ends are inclusive.
+func (ix *Index) Near(center Point, km float64, from, to time.Time) []Match {
+	matches := []Match{}
+	ix.tree.within(toVec(center), chord(km), func(i int) {
+		// The tree works on chords; measure the real distance
+		distance := Distance(center, ix.places[i].Point)
+		if distance > km {
+			return
+		}
+		for _, m := range ix.concerts[i] {
+			if (!from.IsZero() && m.Date.Before(from)) || (!to.IsZero() && m.Date.After(to)) {
+				continue
+			}
+			m.DistanceKm = distance
+			matches = append(matches, m)
+		}
+	})
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].DistanceKm != matches[j].DistanceKm {
+			return matches[i].DistanceKm < matches[j].DistanceKm
+		}
+		if !matches[i].Date.Equal(matches[j].Date) {
+			return matches[i].Date.Before(matches[j].Date)
+		}
+		return matches[i].Artist.ID < matches[j].Artist.ID
+	})
+	return matches
+}
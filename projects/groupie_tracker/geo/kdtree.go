@@ -0,0 +1,98 @@
+package geo
+
+import (
+	"math"
+	"sort"
+)
+
+// vec is a point on the unit sphere. Straight-line (chord) distances
+// between vecs grow with great-circle distances, so a plain 3-d tree can
+// answer radius queries without special cases at the poles or at the
+// antimeridian.
+type vec [3]float64
+
+func toVec(p Point) vec {
+	lat, lon := radians(p.Lat), radians(p.Lon)
+	return vec{math.Cos(lat) * math.Cos(lon), math.Cos(lat) * math.Sin(lon), math.Sin(lat)}
+}
+
+func (a vec) dist2(b vec) float64 {
+	dx, dy, dz := a[0]-b[0], a[1]-b[1], a[2]-b[2]
+	return dx*dx + dy*dy + dz*dz
+}
+
+// chord is the straight-line distance through the unit sphere between two
+// points km apart on the surface
+func chord(km float64) float64 {
+	angle := math.Min(km/EarthRadiusKm, math.Pi)
+	return 2 * math.Sin(angle/2)
+}
+
+// kdTree is a static 3-d tree stored in an array: the median of each
+// range is its root, split on the node's depth modulo 3
+type kdTree struct {
+	points []vec
+	items  []int // items[i] is the caller's index of points[i]
+}
+
+func newKDTree(points []vec) *kdTree {
+	t := &kdTree{points: append([]vec(nil), points...), items: make([]int, len(points))}
+	for i := range t.items {
+		t.items[i] = i
+	}
+	t.build(0, len(t.points), 0)
+	return t
+}
+
+func (t *kdTree) build(lo, hi, axis int) {
+	if hi-lo <= 1 {
+		return
+	}
+	s := subtree{t, lo, hi, axis}
+	sort.Sort(s)
+	mid := (lo + hi) / 2
+	t.build(lo, mid, (axis+1)%3)
+	t.build(mid+1, hi, (axis+1)%3)
+}
+
+// within calls fn with the caller's index of every point closer than
+// radius (a chord length) to center
+func (t *kdTree) within(center vec, radius float64, fn func(item int)) {
+	t.search(0, len(t.points), 0, center, radius*radius, radius, fn)
+}
+
+func (t *kdTree) search(lo, hi, axis int, center vec, r2, r float64, fn func(int)) {
+	if lo >= hi {
+		return
+	}
+	mid := (lo + hi) / 2
+	p := t.points[mid]
+	if p.dist2(center) <= r2 {
+		fn(t.items[mid])
+	}
+
+	next := (axis + 1) % 3
+	d := center[axis] - p[axis]
+	if d <= r {
+		t.search(lo, mid, next, center, r2, r, fn)
+	}
+	if d >= -r {
+		t.search(mid+1, hi, next, center, r2, r, fn)
+	}
+}
+
+// subtree sorts one range of the tree on an axis
+type subtree struct {
+	t            *kdTree
+	lo, hi, axis int
+}
+
+func (s subtree) Len() int { return s.hi - s.lo }
+func (s subtree) Less(i, j int) bool {
+	return s.t.points[s.lo+i][s.axis] < s.t.points[s.lo+j][s.axis]
+}
+func (s subtree) Swap(i, j int) {
+	i, j = s.lo+i, s.lo+j
+	s.t.points[i], s.t.points[j] = s.t.points[j], s.t.points[i]
+	s.t.items[i], s.t.items[j] = s.t.items[j], s.t.items[i]
+}
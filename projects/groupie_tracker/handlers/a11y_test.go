@@ -0,0 +1,333 @@
+package handlers
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// element is a parsed HTML element; text children are kept as elements
+// with an empty tag
+type element struct {
+	tag      string
+	attrs    map[string]string
+	text     string
+	parent   *element
+	children []*element
+}
+
+func (e *element) attr(name string) (string, bool) {
+	v, ok := e.attrs[name]
+	return v, ok
+}
+
+// textContent is the text of e and its descendants, spaces collapsed
+func (e *element) textContent() string {
+	var b strings.Builder
+	var walk func(*element)
+	walk = func(n *element) {
+		if n.tag == "" {
+			b.WriteString(n.text)
+		}
+		if n.tag == "img" {
+			b.WriteString(n.attrs["alt"])
+		}
+		for _, c := range n.children {
+			walk(c)
+		}
+	}
+	walk(e)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// accessibleName approximates how assistive technology names e
+func (e *element) accessibleName() string {
+	if label, ok := e.attr("aria-label"); ok {
+		return strings.TrimSpace(label)
+	}
+	return e.textContent()
+}
+
+func (e *element) all(tag string) []*element {
+	var found []*element
+	var walk func(*element)
+	walk = func(n *element) {
+		if n.tag == tag || (tag == "*" && n.tag != "") {
+			found = append(found, n)
+		}
+		for _, c := range n.children {
+			walk(c)
+		}
+	}
+	walk(e)
+	return found
+}
+
+func (e *element) inside(tag string) bool {
+	for p := e.parent; p != nil; p = p.parent {
+		if p.tag == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// parseHTML parses a rendered page with encoding/xml's lenient HTML mode,
+// which is enough for the well-formed markup html/template produces
+func parseHTML(t *testing.T, body string) *element {
+	t.Helper()
+	d := xml.NewDecoder(strings.NewReader(body))
+	d.Strict = false
+	d.AutoClose = xml.HTMLAutoClose
+	d.Entity = xml.HTMLEntity
+
+	root := &element{tag: "#document"}
+	current := root
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return root
+		}
+		if err != nil {
+			t.Fatalf("Could not parse page: %v", err)
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			e := &element{tag: strings.ToLower(tok.Name.Local), attrs: make(map[string]string), parent: current}
+			for _, a := range tok.Attr {
+				e.attrs[strings.ToLower(a.Name.Local)] = a.Value
+			}
+			current.children = append(current.children, e)
+			current = e
+		case xml.EndElement:
+			if current.parent != nil {
+				current = current.parent
+			}
+		case xml.CharData:
+			current.children = append(current.children, &element{text: string(tok), parent: current})
+		}
+	}
+}
+
+// checkAccessibility reports the accessibility problems of a page
+func checkAccessibility(doc *element) []string {
+	var problems []string
+	report := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	// Language
+	if html := doc.all("html"); len(html) != 1 || html[0].attrs["lang"] == "" {
+		report("<html> needs a lang attribute")
+	}
+
+	// IDs are unique and every reference points at one
+	ids := make(map[string]bool)
+	for _, e := range doc.all("*") {
+		if id, ok := e.attr("id"); ok {
+			if ids[id] {
+				report("duplicate id %q", id)
+			}
+			ids[id] = true
+		}
+	}
+	for _, e := range doc.all("*") {
+		for _, name := range []string{"aria-labelledby", "aria-describedby", "aria-controls", "for"} {
+			for _, ref := range strings.Fields(e.attrs[name]) {
+				if !ids[ref] {
+					report("<%s %s=%q> refers to a missing id", e.tag, name, ref)
+				}
+			}
+		}
+	}
+
+	// Landmarks and the skip link
+	mains := doc.all("main")
+	if len(mains) != 1 {
+		report("expected one <main>, found %d", len(mains))
+	}
+	links := doc.all("a")
+	if len(links) == 0 || links[0].attrs["class"] != "skip-link" {
+		report("the first link must be the skip link")
+	} else if target := strings.TrimPrefix(links[0].attrs["href"], "#"); len(mains) == 1 && mains[0].attrs["id"] != target {
+		report("the skip link must target <main>, not %q", target)
+	}
+	for _, nav := range doc.all("nav") {
+		if nav.attrs["aria-label"] == "" {
+			report("<nav> needs an aria-label to tell it apart")
+		}
+	}
+
+	// Headings: a single h1 and no skipped levels
+	level := 0
+	h1s := 0
+	for _, e := range doc.all("*") {
+		if len(e.tag) != 2 || e.tag[0] != 'h' || e.tag[1] < '1' || e.tag[1] > '6' {
+			continue
+		}
+		n := int(e.tag[1] - '0')
+		if n == 1 {
+			h1s++
+		}
+		if n > level+1 {
+			report("<%s>%s</%s> skips a heading level", e.tag, e.textContent(), e.tag)
+		}
+		level = n
+		if e.textContent() == "" {
+			report("empty <%s>", e.tag)
+		}
+	}
+	if h1s != 1 {
+		report("expected one <h1>, found %d", h1s)
+	}
+
+	// Images and charts
+	for _, img := range doc.all("img") {
+		if _, ok := img.attr("alt"); !ok {
+			report("<img src=%q> has no alt attribute", img.attrs["src"])
+		}
+	}
+	for _, svg := range doc.all("svg") {
+		if svg.attrs["role"] != "img" || svg.attrs["aria-label"] == "" {
+			report("<svg> needs role=img and an aria-label")
+		}
+	}
+
+	// Links: a name that says where they go
+	targets := make(map[string]string)
+	for _, a := range links {
+		name := a.accessibleName()
+		if name == "" {
+			report("<a href=%q> has no text", a.attrs["href"])
+			continue
+		}
+		href := a.attrs["href"]
+		if prev, ok := targets[name]; ok && prev != href {
+			report("links named %q go to both %q and %q", name, prev, href)
+		}
+		targets[name] = href
+	}
+
+	// Form controls
+	labelled := make(map[string]bool)
+	for _, label := range doc.all("label") {
+		labelled[label.attrs["for"]] = true
+	}
+	for _, input := range doc.all("input") {
+		switch input.attrs["type"] {
+		case "hidden", "submit", "button":
+			continue
+		}
+		if !input.inside("label") && !labelled[input.attrs["id"]] && input.attrs["aria-label"] == "" && input.attrs["aria-labelledby"] == "" {
+			report("<input name=%q> has no label", input.attrs["name"])
+		}
+	}
+	for _, button := range doc.all("button") {
+		if button.accessibleName() == "" {
+			report("<button> has no text")
+		}
+	}
+
+	// Dates and tables
+	for _, tm := range doc.all("time") {
+		_, dateErr := time.Parse(time.DateOnly, tm.attrs["datetime"])
+		_, timeErr := time.Parse(time.RFC3339, tm.attrs["datetime"])
+		if dateErr != nil && timeErr != nil {
+			report("<time>%s</time> needs a datetime attribute", tm.textContent())
+		}
+	}
+	for _, th := range doc.all("th") {
+		if th.attrs["scope"] == "" {
+			report("<th>%s</th> needs a scope", th.textContent())
+		}
+	}
+	return problems
+}
+
+func TestPagesAreAccessible(t *testing.T) {
+	setupCatalog(t)
+
+	// A visitor with a starred artist
+	rec := get(ArtistHandler, "/artist?id=1", nil)
+	cookie := rec.Result().Cookies()[0]
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(cookie)
+	sessionID, _ := sessions.ID(req)
+	favs.Add(sessionID, 1)
+	withCookie := map[string]string{"Cookie": cookie.String()}
+	SetAdmin(&fakeAdmin{}, "s3cret", snapshotDir(t))
+	t.Cleanup(func() { SetAdmin(nil, "", "") })
+
+	pages := []struct {
+		name    string
+		handler http.HandlerFunc
+		target  string
+		headers map[string]string
+	}{
+		{"home", HomeHandler, "/", nil},
+		{"artist", ArtistHandler, "/artist?id=2", nil},
+		{"favorite artist", ArtistHandler, "/artist?id=1", withCookie},
+		{"search", SearchHandler, "/search?q=roger", nil},
+		{"no results", SearchHandler, "/search?q=nobody", nil},
+		{"compare", CompareHandler, "/compare?ids=1&ids=2", nil},
+		{"favorites", FavoritesHandler, "/favorites", withCookie},
+		{"no favorites", FavoritesHandler, "/favorites", nil},
+		{"stats", StatsHandler, "/stats", nil},
+		{"admin", AdminHandler, "/admin?result=dropped", basicAuth("admin", "s3cret")},
+		{"error", ArtistHandler, "/artist?id=99", nil},
+		{"arabic", HomeHandler, "/", map[string]string{"Accept-Language": "ar"}},
+	}
+	for _, page := range pages {
+		t.Run(page.name, func(t *testing.T) {
+			rec := get(page.handler, page.target, page.headers)
+			if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/html") {
+				t.Fatalf("Expected an HTML page, got %d %s", rec.Code, rec.Header().Get("Content-Type"))
+			}
+			for _, problem := range checkAccessibility(parseHTML(t, rec.Body.String())) {
+				t.Error(problem)
+			}
+		})
+	}
+
+	t.Run("member", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/members/roger-waters", nil)
+		req.SetPathValue("slug", "roger-waters")
+		rec := httptest.NewRecorder()
+		MemberHandler(rec, req)
+		for _, problem := range checkAccessibility(parseHTML(t, rec.Body.String())) {
+			t.Error(problem)
+		}
+	})
+}
+
+// The checker itself must catch the mistakes it is meant to
+func TestCheckAccessibilityFindsProblems(t *testing.T) {
+	page := `<!DOCTYPE html><html><body>
+		<nav><a href="/a">Details</a><a href="/b">Details</a></nav>
+		<main><h1>Title</h1><h3>Skipped</h3>
+		<img src="x.png"><input name="q"><label for="nope">Lost</label>
+		<time>Today</time><a href="/c"></a></main></body></html>`
+
+	problems := strings.Join(checkAccessibility(parseHTML(t, page)), "\n")
+	for _, want := range []string{
+		"lang attribute",
+		"skip link",
+		"<nav> needs an aria-label",
+		"skips a heading level",
+		"has no alt attribute",
+		`<input name="q"> has no label`,
+		`for="nope"> refers to a missing id`,
+		`links named "Details" go to both`,
+		`<a href="/c"> has no text`,
+		"needs a datetime attribute",
+	} {
+		if !strings.Contains(problems, want) {
+			t.Errorf("Expected the checker to report %q, got:\n%s", want, problems)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"groupie_tracker/catalog"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AdminStore is what the admin pages need from the catalog. The server
+// uses *catalog.Store.
+type AdminStore interface {
+	Status() catalog.Status
+	Refresh() error
+	LoadSnapshotFile(path string) error
+}
+
+// admin backs the /admin pages; they are disabled without a token
+var admin struct {
+	store       AdminStore
+	token       string
+	snapshotDir string // the snapshots that can be loaded, empty for none
+}
+
+// adminUser is the user name to give with the token for basic auth
+const adminUser = "admin"
+
+// SetAdmin enables the admin pages, protected by token. With an empty
+// token they answer 404. The snapshot files in snapshotDir can be loaded
+// from the page; files elsewhere cannot.
+func SetAdmin(s AdminStore, token, snapshotDir string) {
+	admin.store = s
+	admin.token = token
+	admin.snapshotDir = snapshotDir
+}
+
+// adminResults are the messages shown after an action, by ?result=
+var adminResults = map[string]string{
+	"refreshed":      "Catalog refreshed.",
+	"refresh-failed": "Refresh failed, the previous catalog is kept.",
+	"dropped":        "Caches dropped, they are rebuilt on the next request.",
+	"images-dropped": "Image cache dropped, pictures are fetched again when next shown.",
+	"loaded":         "Snapshot loaded.",
+	"load-failed":    "Could not load the snapshot, see the server log.",
+}
+
+type AdminData struct {
+	Status    catalog.Status
+	Loaded    bool
+	FetchedAt time.Time     // of the catalog being served
+	CacheAge  time.Duration // since FetchedAt
+	Latency   time.Duration // of the last refresh, rounded
+	Stats     catalog.Stats
+	Caches    []AdminCache
+	Images    AdminImages
+	Snapshots []AdminSnapshot // files that can be loaded, newest first
+	Result    string
+	CSRFToken string
+}
+
+// AdminCache is a cache derived from the catalog
+type AdminCache struct {
+	Name  string
+	Built bool // for the catalog being served
+}
+
+// AdminImages is the state of the artist picture cache
+type AdminImages struct {
+	Enabled bool
+	Count   int
+	KiB     int64
+}
+
+// AdminSnapshot is a snapshot file that can be loaded
+type AdminSnapshot struct {
+	Name     string
+	Modified time.Time
+	KiB      int64
+}
+
+// AdminHandler shows the state of the catalog and its caches
+func AdminHandler(w http.ResponseWriter, r *http.Request) {
+	// 1. Check the credentials
+	if _, ok := adminAuthorized(w, r); !ok {
+		return
+	}
+	sessionID := sessions.Ensure(w, r)
+
+	// 2. Gather the state of the store and the catalog it serves
+	status := admin.store.Status()
+	data := AdminData{
+		Status:    status,
+		Latency:   status.LastDuration.Round(time.Millisecond),
+		Result:    adminResults[r.URL.Query().Get("result")],
+		CSRFToken: sessions.CSRFToken(sessionID),
+	}
+	if cat, err := store.Current(); err == nil {
+		data.Loaded = true
+		data.FetchedAt = cat.FetchedAt
+		data.CacheAge = time.Since(cat.FetchedAt).Round(time.Second)
+		data.Stats = statsFor(cat)
+		data.Caches = adminCaches(cat)
+	}
+	if images != nil {
+		count, size := images.Stats()
+		data.Images = AdminImages{Enabled: true, Count: count, KiB: (size + 1023) / 1024}
+	}
+	snapshots, err := snapshotFiles()
+	if err != nil {
+		log.Printf("Error listing snapshots: %v", err)
+	}
+	data.Snapshots = snapshots
+
+	// 3. Render admin.html template, never cached since it changes on every action
+	w.Header().Set("Cache-Control", "no-store")
+	renderTemplate(w, r, "admin.html", data)
+}
+
+// AdminActionHandler runs the action of /admin/{action} and goes back to
+// /admin with its result
+func AdminActionHandler(w http.ResponseWriter, r *http.Request) {
+	// 1. Check the credentials; browsers send basic auth on their own,
+	// so their forms also need a CSRF token
+	bearer, ok := adminAuthorized(w, r)
+	if !ok {
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		RenderError(w, r, http.StatusMethodNotAllowed, "Admin actions can only be run with POST")
+		return
+	}
+	if !bearer {
+		sessionID, ok := sessions.ID(r)
+		if !ok || !sessions.ValidCSRF(sessionID, r.PostFormValue("csrf")) {
+			RenderError(w, r, http.StatusForbidden, "Invalid or missing CSRF token")
+			return
+		}
+	}
+
+	// 2. Run the action
+	var result string
+	switch r.PathValue("action") {
+	case "refresh":
+		result = "refreshed"
+		if err := admin.store.Refresh(); err != nil {
+			log.Printf("Error refreshing catalog from admin: %v", err)
+			result = "refresh-failed"
+		}
+	case "drop-caches":
+		dropCaches()
+		result = "dropped"
+	case "drop-images":
+		if images == nil {
+			RenderError(w, r, http.StatusNotFound, "Page not found")
+			return
+		}
+		images.Drop()
+		result = "images-dropped"
+	case "load-snapshot":
+		// Only files listed on the page can be loaded, never a path
+		path, ok := snapshotPath(r.PostFormValue("file"))
+		if !ok {
+			RenderError(w, r, http.StatusBadRequest, "Unknown snapshot file")
+			return
+		}
+		result = "loaded"
+		if err := admin.store.LoadSnapshotFile(path); err != nil {
+			log.Printf("Error loading snapshot from admin: %v", err)
+			result = "load-failed"
+		}
+	default:
+		RenderError(w, r, http.StatusNotFound, "Page not found")
+		return
+	}
+	log.Printf("Admin action %s: %s", r.PathValue("action"), result)
+
+	// 3. Show the result
+	http.Redirect(w, r, "/admin?result="+result, http.StatusSeeOther)
+}
+
+// snapshotFiles lists the JSON files of the snapshot directory, newest first
+func snapshotFiles() ([]AdminSnapshot, error) {
+	if admin.snapshotDir == "" {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(admin.snapshotDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshots []AdminSnapshot
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		snapshots = append(snapshots, AdminSnapshot{
+			Name:     entry.Name(),
+			Modified: info.ModTime(),
+			KiB:      (info.Size() + 1023) / 1024,
+		})
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Modified.After(snapshots[j].Modified) })
+	return snapshots, nil
+}
+
+// snapshotPath resolves a file name picked on the admin page. It must be
+// one of snapshotFiles, which rules out paths and other directories.
+func snapshotPath(name string) (string, bool) {
+	snapshots, err := snapshotFiles()
+	if err != nil {
+		log.Printf("Error listing snapshots: %v", err)
+		return "", false
+	}
+	for _, snapshot := range snapshots {
+		if snapshot.Name == name {
+			return filepath.Join(admin.snapshotDir, name), true
+		}
+	}
+	return "", false
+}
+
+// adminAuthorized checks the admin token, given as a bearer token or as
+// the basic auth password, and answers the request when it is missing
+// or wrong. bearer tells which of the two was used.
+func adminAuthorized(w http.ResponseWriter, r *http.Request) (bearer, ok bool) {
+	if admin.token == "" {
+		RenderError(w, r, http.StatusNotFound, "Page not found")
+		return false, false
+	}
+
+	if token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); found {
+		if validAdminToken(token) {
+			return true, true
+		}
+	} else if user, password, found := r.BasicAuth(); found {
+		userOK := subtle.ConstantTimeCompare([]byte(user), []byte(adminUser)) == 1
+		if validAdminToken(password) && userOK {
+			return false, true
+		}
+	}
+
+	w.Header().Set("WWW-Authenticate", `Basic realm="Groupie Tracker admin", charset="UTF-8"`)
+	RenderError(w, r, http.StatusUnauthorized, "Authentication required")
+	return false, false
+}
+
+func validAdminToken(token string) bool {
+	return subtle.ConstantTimeCompare([]byte(token), []byte(admin.token)) == 1
+}
+
+// adminCaches tells which derived caches have been built for cat
+func adminCaches(cat *catalog.Catalog) []AdminCache {
+	caches := make([]AdminCache, len(derivedCaches))
+	for i, c := range derivedCaches {
+		caches[i] = AdminCache{c.Name(), c.Built(cat)}
+	}
+	return caches
+}
+
+// dropCaches forgets every cache derived from the catalog, so each is
+// rebuilt on the next request that needs it
+func dropCaches() {
+	for _, c := range derivedCaches {
+		c.Drop()
+	}
+}
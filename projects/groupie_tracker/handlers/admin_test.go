@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"errors"
+	"groupie_tracker/catalog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeAdmin records the admin actions run against it
+type fakeAdmin struct {
+	status     catalog.Status
+	refreshErr error
+	loadErr    error
+	refreshes  int
+	loaded     []string
+}
+
+func (f *fakeAdmin) Status() catalog.Status { return f.status }
+func (f *fakeAdmin) Refresh() error         { f.refreshes++; return f.refreshErr }
+func (f *fakeAdmin) LoadSnapshotFile(path string) error {
+	f.loaded = append(f.loaded, path)
+	return f.loadErr
+}
+
+func setupAdmin(t *testing.T) *fakeAdmin {
+	t.Helper()
+
+	setupCatalog(t)
+	fake := &fakeAdmin{status: catalog.Status{
+		LastAttempt:  time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC),
+		LastDuration: 1234567 * time.Microsecond,
+		LastError:    errors.New("upstream returned 502"),
+		SnapshotPath: "catalog.snapshot.json",
+	}}
+	SetAdmin(fake, "s3cret", snapshotDir(t))
+	t.Cleanup(func() { SetAdmin(nil, "", "") })
+	return fake
+}
+
+// snapshotDir makes a snapshot directory with two snapshots, older first,
+// and files that are not snapshots
+func snapshotDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for i, name := range []string{"2026-09-30.json", "catalog.snapshot.json", "notes.txt"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("{}"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		modified := time.Date(2026, 9, 30+i, 12, 0, 0, 0, time.UTC)
+		os.Chtimes(path, modified, modified)
+	}
+	os.Mkdir(filepath.Join(dir, "old.json"), 0o755)
+	return dir
+}
+
+func basicAuth(user, password string) map[string]string {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.SetBasicAuth(user, password)
+	return map[string]string{"Authorization": req.Header.Get("Authorization")}
+}
+
+func TestAdminAuthentication(t *testing.T) {
+	setupAdmin(t)
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    int
+	}{
+		{"no credentials", nil, http.StatusUnauthorized},
+		{"wrong password", basicAuth("admin", "guess"), http.StatusUnauthorized},
+		{"wrong user", basicAuth("root", "s3cret"), http.StatusUnauthorized},
+		{"wrong token", map[string]string{"Authorization": "Bearer guess"}, http.StatusUnauthorized},
+		{"basic auth", basicAuth("admin", "s3cret"), http.StatusOK},
+		{"bearer token", map[string]string{"Authorization": "Bearer s3cret"}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		rec := get(AdminHandler, "/admin", tt.headers)
+		if rec.Code != tt.want {
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.want, rec.Code)
+		}
+		if tt.want == http.StatusUnauthorized && !strings.HasPrefix(rec.Header().Get("WWW-Authenticate"), "Basic ") {
+			t.Errorf("%s: expected a basic auth challenge", tt.name)
+		}
+	}
+
+	// Without a token the admin pages do not exist
+	SetAdmin(&fakeAdmin{}, "", "")
+	if rec := get(AdminHandler, "/admin", basicAuth("admin", "")); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 with admin disabled, got %d", rec.Code)
+	}
+}
+
+func TestAdminHandler(t *testing.T) {
+	setupAdmin(t)
+	indexFor(testCatalog(time.Now())) // another catalog's index does not count
+
+	rec := get(AdminHandler, "/admin?result=refresh-failed", basicAuth("admin", "s3cret"))
+	body := rec.Body.String()
+	for _, want := range []string{
+		"upstream returned 502",
+		"1.235s",
+		`<time datetime="2026-10-01T10:00:00Z">`,
+		"catalog.snapshot.json",
+		"<dd>2</dd>", // artists
+		"Search index: not built yet",
+		"Refresh failed, the previous catalog is kept.",
+		`action="/admin/load-snapshot"`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected admin page to contain %q", want)
+		}
+	}
+	if got := rec.Header().Get("Cache-Control"); got != "no-store" {
+		t.Errorf("Expected the admin page not to be cached, got %q", got)
+	}
+}
+
+func TestAdminActions(t *testing.T) {
+	fake := setupAdmin(t)
+
+	// A browser session with its CSRF token
+	rec := get(AdminHandler, "/admin", basicAuth("admin", "s3cret"))
+	cookie := rec.Result().Cookies()[0]
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(cookie)
+	sessionID, _ := sessions.ID(req)
+	csrf := sessions.CSRFToken(sessionID)
+
+	post := func(action, token string, headers map[string]string) *httptest.ResponseRecorder {
+		form := url.Values{"csrf": {token}}
+		req := httptest.NewRequest(http.MethodPost, "/admin/"+action, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(cookie)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		req.SetPathValue("action", action)
+		rec := httptest.NewRecorder()
+		AdminActionHandler(rec, req)
+		return rec
+	}
+	browser := basicAuth("admin", "s3cret")
+
+	if rec := post("refresh", csrf, browser); rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/admin?result=refreshed" || fake.refreshes != 1 {
+		t.Errorf("Expected a refresh, got %d %q after %d refreshes", rec.Code, rec.Header().Get("Location"), fake.refreshes)
+	}
+
+	cat := testCatalog(time.Time{})
+	statsFor(cat)
+	if rec := post("drop-caches", csrf, browser); rec.Header().Get("Location") != "/admin?result=dropped" || catalogStats.Built(cat) {
+		t.Errorf("Expected the caches to be dropped, got %q", rec.Header().Get("Location"))
+	}
+
+	// Scripts using the token need no CSRF token; browsers do
+	if rec := post("refresh", "", map[string]string{"Authorization": "Bearer s3cret"}); rec.Code != http.StatusSeeOther {
+		t.Errorf("Expected a bearer token to be enough, got %d", rec.Code)
+	}
+	if rec := post("refresh", "", browser); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 without a CSRF token, got %d", rec.Code)
+	}
+	if rec := post("refresh", csrf, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without credentials, got %d", rec.Code)
+	}
+	if rec := post("reboot", csrf, browser); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown action, got %d", rec.Code)
+	}
+	if fake.refreshes != 2 {
+		t.Errorf("Expected 2 refreshes, got %d", fake.refreshes)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/admin/refresh", nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	req.SetPathValue("action", "refresh")
+	rec = httptest.NewRecorder()
+	AdminActionHandler(rec, req)
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for GET, got %d", rec.Code)
+	}
+}
+
+func TestAdminImageCache(t *testing.T) {
+	setupAdmin(t)
+	bearer := map[string]string{"Authorization": "Bearer s3cret"}
+	dropImages := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/admin/drop-images", nil)
+		req.Header.Set("Authorization", "Bearer s3cret")
+		req.SetPathValue("action", "drop-images")
+		rec := httptest.NewRecorder()
+		AdminActionHandler(rec, req)
+		return rec
+	}
+
+	// Without an image cache there is nothing to drop
+	if body := get(AdminHandler, "/admin", bearer).Body.String(); strings.Contains(body, "/admin/drop-images") {
+		t.Error("Expected no drop image cache button without an image cache")
+	}
+	if rec := dropImages(); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 without an image cache, got %d", rec.Code)
+	}
+
+	setupImages(t)
+	image("1", nil)
+	body := get(AdminHandler, "/admin", bearer).Body.String()
+	if !strings.Contains(body, "Pictures: 1 (1 KiB)") || !strings.Contains(body, `action="/admin/drop-images"`) {
+		t.Errorf("Expected the image cache state and its button, got %s", body)
+	}
+
+	if rec := dropImages(); rec.Header().Get("Location") != "/admin?result=images-dropped" {
+		t.Errorf("Expected the image cache to be dropped, got %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+	if count, _ := images.Stats(); count != 0 {
+		t.Errorf("Expected no cached picture, got %d", count)
+	}
+}
+
+func TestAdminLoadSnapshot(t *testing.T) {
+	fake := setupAdmin(t)
+	load := func(file string) *httptest.ResponseRecorder {
+		form := url.Values{"file": {file}}
+		req := httptest.NewRequest(http.MethodPost, "/admin/load-snapshot", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Authorization", "Bearer s3cret")
+		req.SetPathValue("action", "load-snapshot")
+		rec := httptest.NewRecorder()
+		AdminActionHandler(rec, req)
+		return rec
+	}
+
+	// The page offers the snapshots of the directory, newest first
+	body := get(AdminHandler, "/admin", basicAuth("admin", "s3cret")).Body.String()
+	newest, older := strings.Index(body, `<option value="catalog.snapshot.json">`), strings.Index(body, `<option value="2026-09-30.json">`)
+	if newest < 0 || older < newest {
+		t.Errorf("Expected both snapshots, newest first, got %s", body)
+	}
+	if strings.Contains(body, "notes.txt") || strings.Contains(body, `value="old.json"`) {
+		t.Error("Expected only JSON files to be offered")
+	}
+
+	if rec := load("2026-09-30.json"); rec.Header().Get("Location") != "/admin?result=loaded" {
+		t.Errorf("Expected the snapshot to be loaded, got %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+	if len(fake.loaded) != 1 || fake.loaded[0] != filepath.Join(admin.snapshotDir, "2026-09-30.json") {
+		t.Errorf("Expected the file of the snapshot directory to be loaded, got %v", fake.loaded)
+	}
+
+	fake.loadErr = errors.New("checksum mismatch")
+	if rec := load("catalog.snapshot.json"); rec.Header().Get("Location") != "/admin?result=load-failed" {
+		t.Errorf("Expected a failed snapshot load, got %q", rec.Header().Get("Location"))
+	}
+
+	// Nothing outside the list can be loaded
+	for _, file := range []string{"", "notes.txt", "old.json", "../catalog.snapshot.json", "/etc/passwd", filepath.Join(admin.snapshotDir, "2026-09-30.json")} {
+		if rec := load(file); rec.Code != http.StatusBadRequest {
+			t.Errorf("%q: expected 400, got %d", file, rec.Code)
+		}
+	}
+	if len(fake.loaded) != 2 {
+		t.Errorf("Expected 2 loads, got %v", fake.loaded)
+	}
+
+	// Without a snapshot directory there is nothing to load
+	SetAdmin(fake, "s3cret", "")
+	if body := get(AdminHandler, "/admin", basicAuth("admin", "s3cret")).Body.String(); strings.Contains(body, "/admin/load-snapshot") {
+		t.Error("Expected no load snapshot form without a snapshot directory")
+	}
+	if rec := load("catalog.snapshot.json"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without a snapshot directory, got %d", rec.Code)
+	}
+}
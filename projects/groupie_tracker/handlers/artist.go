@@ -1,19 +1,22 @@
 package handlers
 
 import (
-	"groupie_tracker/api"
+	"groupie_tracker/i18n"
 	"groupie_tracker/models"
-	"html/template"
 	"log"
 	"net/http"
+	"slices"
 	"strconv"
 )
 
 type ArtistData struct {
-	Artist    models.Artist
-	Locations models.Locations
-	Dates     models.Dates
-	Relations models.Relation
+	Artist     models.Artist
+	Locations  models.Locations
+	Dates      models.Dates
+	Relations  models.Relation
+	Similar    []SimilarArtist
+	IsFavorite bool
+	CSRFToken  string
 }
 
 // ArtistHandler displays individual artist details
@@ -23,60 +26,35 @@ func ArtistHandler(w http.ResponseWriter, r *http.Request) {
 
 	// FIX: Check explicitly for missing id param before Atoi
 	if idStr == "" {
-		RenderError(w, http.StatusBadRequest, "Missing artist ID")
+		RenderError(w, r, http.StatusBadRequest, "Missing artist ID")
 		return
 	}
 
 	targetId, err := strconv.Atoi(idStr)
 	if err != nil || targetId < 1 {
-		RenderError(w, http.StatusBadRequest, "Invalid artist ID")
+		RenderError(w, r, http.StatusBadRequest, "Invalid artist ID")
 		return
 	}
 
-	// 2. Fetch all artists
-	artists, err := api.GetArtists()
+	// 2. Get the catalog
+	cat, err := store.Current()
 	if err != nil {
-		log.Printf("Error fetching artists: %v", err)
-		RenderError(w, http.StatusInternalServerError, "Failed to fetch artists data")
+		log.Printf("Error loading catalog: %v", err)
+		RenderError(w, r, http.StatusServiceUnavailable, "Artist data is not available yet")
 		return
 	}
 
 	// 3. Find the artist with matching ID
-	var selectedArtist models.Artist
-	found := false
-	for _, artist := range artists {
-		if targetId == artist.ID {
-			selectedArtist = artist
-			found = true
-			break
-		}
-	}
+	selectedArtist, found := cat.Artist(targetId)
 	if !found {
-		RenderError(w, http.StatusNotFound, "Artist not found")
-		return
-	}
-
-	// 4. Fetch additional data — FIX: each has its own error check
-	locations, err := api.GetLocations(selectedArtist.Locations)
-	if err != nil {
-		log.Printf("Error fetching locations for artist %d: %v", targetId, err)
-		RenderError(w, http.StatusInternalServerError, "Failed to fetch location data")
+		RenderError(w, r, http.StatusNotFound, "Artist not found")
 		return
 	}
 
-	dates, err := api.GetDates(selectedArtist.ConcertDates)
-	if err != nil {
-		log.Printf("Error fetching dates for artist %d: %v", targetId, err)
-		RenderError(w, http.StatusInternalServerError, "Failed to fetch date data")
-		return
-	}
-
-	relations, err := api.GetRelations(selectedArtist.Relations)
-	if err != nil {
-		log.Printf("Error fetching relations for artist %d: %v", targetId, err)
-		RenderError(w, http.StatusInternalServerError, "Failed to fetch relation data")
-		return
-	}
+	// 4. Look up additional data — the catalog is keyed by artist ID
+	locations := cat.Locations[targetId]
+	dates := cat.Dates[targetId]
+	relations := cat.Relations[targetId]
 
 	// 5. Combine data into a struct for template
 	data := ArtistData{
@@ -84,18 +62,22 @@ func ArtistHandler(w http.ResponseWriter, r *http.Request) {
 		Locations: locations,
 		Dates:     dates,
 		Relations: relations,
+		Similar:   similarArtists(cat, targetId),
 	}
 
-	// 6. Render artist.html template
-	tmpl, err := template.ParseFiles("./templates/artist.html")
-	if err != nil {
-		log.Printf("Error parsing template: %v", err)
-		RenderError(w, http.StatusInternalServerError, "Template error")
-		return
+	sessionID := sessions.Ensure(w, r)
+	data.CSRFToken = sessions.CSRFToken(sessionID)
+	if ids, err := favs.List(sessionID); err == nil {
+		data.IsFavorite = slices.Contains(ids, targetId)
+	} else {
+		log.Printf("Error listing favorites: %v", err)
 	}
 
-	err = tmpl.Execute(w, data)
-	if err != nil {
-		log.Printf("Error executing template: %v", err)
+	// The page also depends on the language and the favorite button
+	if checkNotModified(w, r, cat.FetchedAt, "artist", targetId, i18n.Negotiate(r).Tag, sessionID, data.IsFavorite) {
+		return
 	}
+
+	// 6. Render artist.html template
+	renderTemplate(w, r, "artist.html", data)
 }
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// startedAt is part of every page ETag: templates and translations can
+// only change with a restart, so pages cached before it are stale
+var startedAt = time.Now()
+
+// checkNotModified sets the validators of a rendered page and answers
+// 304 Not Modified when the client's copy is still current. The ETag is
+// derived from parts, which must include everything the page depends on
+// besides the catalog (locale, session, ...). It reports whether the 304
+// was sent, in which case the handler must stop.
+func checkNotModified(w http.ResponseWriter, r *http.Request, modified time.Time, parts ...any) bool {
+	sum := sha256.Sum256([]byte(fmt.Sprint(append(parts, modified.UnixNano(), startedAt.UnixNano())...)))
+	etag := `W/"` + hex.EncodeToString(sum[:8]) + `"`
+
+	// Pages are personalised and must be revalidated on every visit
+	h := w.Header()
+	h.Set("ETag", etag)
+	h.Set("Cache-Control", "private, no-cache")
+	h.Add("Vary", "Accept-Language, Cookie")
+	if lastModified := latest(modified, startedAt); !lastModified.IsZero() {
+		h.Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	// If-None-Match wins over If-Modified-Since when both are sent (RFC 9110)
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		if !etagMatches(inm, etag) {
+			return false
+		}
+	} else if ims := r.Header.Get("If-Modified-Since"); ims != "" {
+		since, err := http.ParseTime(ims)
+		if err != nil || latest(modified, startedAt).Truncate(time.Second).After(since) {
+			return false
+		}
+	} else {
+		return false
+	}
+
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// etagMatches does the weak comparison If-None-Match requires
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+func latest(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
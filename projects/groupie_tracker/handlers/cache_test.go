@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func get(h http.HandlerFunc, target string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	h(rec, req)
+	return rec
+}
+
+func TestHomeConditionalRequests(t *testing.T) {
+	s := setupCatalog(t)
+
+	first := get(HomeHandler, "/", nil)
+	etag, lastModified := first.Header().Get("ETag"), first.Header().Get("Last-Modified")
+	if first.Code != http.StatusOK || !strings.HasPrefix(etag, `W/"`) || lastModified == "" {
+		t.Fatalf("Expected 200 with a weak ETag and Last-Modified, got %d %q %q", first.Code, etag, lastModified)
+	}
+
+	rec := get(HomeHandler, "/", map[string]string{"If-None-Match": etag})
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Errorf("Expected empty 304 for a matching ETag, got %d with %d bytes", rec.Code, rec.Body.Len())
+	}
+
+	rec = get(HomeHandler, "/", map[string]string{"If-Modified-Since": lastModified})
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("Expected 304 for If-Modified-Since, got %d", rec.Code)
+	}
+
+	rec = get(HomeHandler, "/", map[string]string{"If-None-Match": etag, "Accept-Language": "ar"})
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected 200 for another language, got %d", rec.Code)
+	}
+
+	// A refresh makes the cached copy stale
+	s.catalog = testCatalog(time.Now().Add(time.Hour))
+	rec = get(HomeHandler, "/", map[string]string{"If-None-Match": etag})
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected 200 after a catalog refresh, got %d", rec.Code)
+	}
+	rec = get(HomeHandler, "/", map[string]string{"If-Modified-Since": lastModified})
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected 200 for If-Modified-Since before the refresh, got %d", rec.Code)
+	}
+}
+
+func TestArtistConditionalRequests(t *testing.T) {
+	setupCatalog(t)
+
+	first := get(ArtistHandler, "/artist?id=1", nil)
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" {
+		t.Fatalf("Expected 200 with an ETag, got %d %q", first.Code, etag)
+	}
+
+	// Without the session cookie the visitor gets a new session, so the page differs
+	rec := get(ArtistHandler, "/artist?id=1", map[string]string{"If-None-Match": etag})
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected 200 for a different session, got %d", rec.Code)
+	}
+
+	cookie := first.Result().Cookies()[0]
+	rec = get(ArtistHandler, "/artist?id=1", map[string]string{"If-None-Match": etag, "Cookie": cookie.String()})
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("Expected 304 for the same session, got %d", rec.Code)
+	}
+
+	rec = get(ArtistHandler, "/artist?id=2", map[string]string{"If-None-Match": etag, "Cookie": cookie.String()})
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected 200 for another artist, got %d", rec.Code)
+	}
+}
+
+func TestCompress(t *testing.T) {
+	setupCatalog(t)
+	h := Compress(http.HandlerFunc(HomeHandler))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip, br")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("Expected a gzip response, got headers %v", rec.Header())
+	}
+	zr, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatalf("Expected a valid gzip stream, got: %v", err)
+	}
+	body, _ := io.ReadAll(zr)
+	if !strings.Contains(string(body), "Pink Floyd") {
+		t.Error("Expected the decompressed page to list the artists")
+	}
+
+	// 304s have no body to compress
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	req.Header.Set("If-None-Match", rec.Header().Get("ETag"))
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified || rec.Header().Get("Content-Encoding") != "" {
+		t.Errorf("Expected an uncompressed 304, got %d %q", rec.Code, rec.Header().Get("Content-Encoding"))
+	}
+
+	// Clients that do not ask for gzip get plain text
+	rec = get(func(w http.ResponseWriter, r *http.Request) { h.ServeHTTP(w, r) }, "/", nil)
+	if rec.Header().Get("Content-Encoding") != "" || !strings.Contains(rec.Body.String(), "Queen") {
+		t.Errorf("Expected an uncompressed page, got encoding %q", rec.Header().Get("Content-Encoding"))
+	}
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"fmt"
+	"groupie_tracker/ics"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// writeCalendar sends the calendar with headers calendar apps expect
+func writeCalendar(w http.ResponseWriter, filename string, cal ics.Calendar) {
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", filename))
+
+	if err := ics.Write(w, cal); err != nil {
+		log.Printf("Error writing calendar: %v", err)
+	}
+}
+
+// ArtistCalendarHandler serves /artists/{id}/concerts.ics
+func ArtistCalendarHandler(w http.ResponseWriter, r *http.Request) {
+	// 1. Validate the artist ID from the path
+	artistID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || artistID < 1 {
+		RenderError(w, r, http.StatusBadRequest, "Invalid artist ID")
+		return
+	}
+
+	cat, err := store.Current()
+	if err != nil {
+		log.Printf("Error loading catalog: %v", err)
+		RenderError(w, r, http.StatusServiceUnavailable, "Artist data is not available yet")
+		return
+	}
+
+	artist, found := cat.Artist(artistID)
+	if !found {
+		RenderError(w, r, http.StatusNotFound, "Artist not found")
+		return
+	}
+
+	// 2. One all-day event per concert date
+	writeCalendar(w, fmt.Sprintf("artist-%d-concerts.ics", artistID), ics.Calendar{
+		Name:   artist.Name + " - Concerts",
+		Events: ics.ConcertEvents(artist, cat.Concerts(artistID)),
+		Stamp:  cat.FetchedAt,
+	})
+}
+
+// FavoritesCalendarHandler serves the concerts of every starred artist.
+// Calendar apps subscribe without cookies, so ?token= from the favorites
+// page is accepted as well as the session cookie.
+func FavoritesCalendarHandler(w http.ResponseWriter, r *http.Request) {
+	// 1. Identify the visitor; a token, when given, must be valid even
+	// if there is a session cookie
+	var sessionID string
+	var ok bool
+	if token := r.URL.Query().Get("token"); token != "" {
+		sessionID, ok = sessions.FeedSession(token)
+	} else {
+		sessionID, ok = sessions.ID(r)
+	}
+	if !ok {
+		RenderError(w, r, http.StatusForbidden, "Missing or invalid feed token")
+		return
+	}
+
+	ids, err := favs.List(sessionID)
+	if err != nil {
+		log.Printf("Error listing favorites: %v", err)
+		RenderError(w, r, http.StatusInternalServerError, "Failed to load favorites")
+		return
+	}
+
+	cat, err := store.Current()
+	if err != nil {
+		log.Printf("Error loading catalog: %v", err)
+		RenderError(w, r, http.StatusServiceUnavailable, "Artist data is not available yet")
+		return
+	}
+
+	// 2. Merge the concerts of all starred artists
+	cal := ics.Calendar{Name: "My Artists - Concerts", Stamp: cat.FetchedAt}
+	for _, id := range ids {
+		artist, found := cat.Artist(id)
+		if !found {
+			continue
+		}
+		cal.Events = append(cal.Events, ics.ConcertEvents(artist, cat.Concerts(id))...)
+	}
+
+	writeCalendar(w, "favorites.ics", cal)
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"groupie_tracker/session"
+)
+
+func TestArtistCalendarHandler(t *testing.T) {
+	setupCatalog(t)
+
+	calendar := func(id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/artists/"+id+"/concerts.ics", nil)
+		req.SetPathValue("id", id)
+		rec := httptest.NewRecorder()
+		ArtistCalendarHandler(rec, req)
+		return rec
+	}
+
+	rec := calendar("1")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "text/calendar; charset=utf-8" {
+		t.Errorf("Expected a calendar, got %q", got)
+	}
+	if got := rec.Header().Get("Content-Disposition"); !strings.Contains(got, `filename="artist-1-concerts.ics"`) {
+		t.Errorf("Expected the artist's file name, got %q", got)
+	}
+	body := rec.Body.String()
+	if !strings.HasPrefix(body, "BEGIN:VCALENDAR\r\n") || !strings.Contains(body, "Queen") || strings.Count(body, "BEGIN:VEVENT") != 1 {
+		t.Errorf("Expected one concert of Queen, got %q", body)
+	}
+
+	if rec := calendar("99"); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown artist, got %d", rec.Code)
+	}
+	if rec := calendar("x"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid ID, got %d", rec.Code)
+	}
+}
+
+func TestFavoritesCalendarHandler(t *testing.T) {
+	setupCatalog(t)
+
+	// A visitor who starred Pink Floyd
+	cookie := get(ArtistHandler, "/artist?id=1", nil).Result().Cookies()[0]
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(cookie)
+	sessionID, _ := sessions.ID(req)
+	favs.Add(sessionID, 2)
+	token := sessions.FeedToken(sessionID)
+	_, signature, _ := strings.Cut(token, ".")
+
+	calendar := func(query string, withCookie bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/favorites.ics"+query, nil)
+		if withCookie {
+			req.AddCookie(cookie)
+		}
+		rec := httptest.NewRecorder()
+		FavoritesCalendarHandler(rec, req)
+		return rec
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		withCookie bool
+		want       int
+	}{
+		{"session cookie", "", true, http.StatusOK},
+		{"feed token", "?token=" + url.QueryEscape(token), false, http.StatusOK},
+		{"neither", "", false, http.StatusForbidden},
+		{"forged token", "?token=" + url.QueryEscape(session.NewManager([]byte("other-secret")).FeedToken(sessionID)), false, http.StatusForbidden},
+		{"token of another session", "?token=" + url.QueryEscape("someone-else."+signature), false, http.StatusForbidden},
+		{"forged token with a cookie", "?token=forged", true, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		rec := calendar(tt.query, tt.withCookie)
+		if rec.Code != tt.want {
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.want, rec.Code)
+			continue
+		}
+		if tt.want != http.StatusOK {
+			continue
+		}
+		if got := rec.Header().Get("Content-Disposition"); !strings.Contains(got, `filename="favorites.ics"`) {
+			t.Errorf("%s: expected favorites.ics, got %q", tt.name, got)
+		}
+		if body := rec.Body.String(); !strings.Contains(body, "Pink Floyd") || strings.Contains(body, "Queen") {
+			t.Errorf("%s: expected only the starred artist's concerts, got %q", tt.name, body)
+		}
+	}
+}
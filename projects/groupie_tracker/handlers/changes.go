@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"encoding/xml"
+	"fmt"
+	"groupie_tracker/catalog"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type ChangesResponse struct {
+	Since      time.Time           `json:"since"`
+	Changelogs []catalog.Changelog `json:"changelogs"`
+}
+
+// parseSince reads the optional ?since= parameter (RFC 3339)
+func parseSince(r *http.Request) (time.Time, error) {
+	sinceStr := r.URL.Query().Get("since")
+	if sinceStr == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, sinceStr)
+}
+
+// ChangesHandler returns the catalog changelogs since a point in time as JSON
+func ChangesHandler(w http.ResponseWriter, r *http.Request) {
+	since, err := parseSince(r)
+	if err != nil {
+		RenderError(w, r, http.StatusBadRequest, "Invalid since parameter, expected RFC 3339")
+		return
+	}
+
+	changelogs := store.ChangesSince(since)
+	if changelogs == nil {
+		changelogs = []catalog.Changelog{}
+	}
+
+	writeJSON(w, http.StatusOK, ChangesResponse{Since: since, Changelogs: changelogs})
+}
+
+// Atom feed elements (RFC 4287), just the parts we fill in
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Author  atomAuthor  `xml:"author"`
+	Link    []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type atomEntry struct {
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Content atomContent `xml:"content"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+// feedID is the permanent ID of the changes feed. It must not depend on
+// the host name or scheme the feed was fetched with, or readers would
+// see every entry again after a proxy or TLS change.
+const feedID = "tag:groupie-tracker,2026:changes"
+
+// ChangesFeedHandler publishes the catalog changelogs as an Atom feed
+func ChangesFeedHandler(w http.ResponseWriter, r *http.Request) {
+	since, err := parseSince(r)
+	if err != nil {
+		RenderError(w, r, http.StatusBadRequest, "Invalid since parameter, expected RFC 3339")
+		return
+	}
+
+	changelogs := store.ChangesSince(since)
+
+	// Links are relative to the feed URL, whichever host served it
+	feed := atomFeed{
+		ID:      feedID,
+		Title:   "Groupie Tracker - Catalog changes",
+		Updated: time.Now().UTC().Format(time.RFC3339),
+		Author:  atomAuthor{Name: "Groupie Tracker"},
+		Link: []atomLink{
+			{Href: "/changes.atom", Rel: "self"},
+			{Href: "/"},
+		},
+	}
+	if len(changelogs) > 0 {
+		feed.Updated = changelogs[len(changelogs)-1].To.UTC().Format(time.RFC3339)
+	}
+
+	// Newest first, as feed readers expect
+	for i := len(changelogs) - 1; i >= 0; i-- {
+		changelog := changelogs[i]
+
+		lines := make([]string, len(changelog.Changes))
+		for j, change := range changelog.Changes {
+			lines[j] = change.String()
+		}
+
+		feed.Entries = append(feed.Entries, atomEntry{
+			ID:      fmt.Sprintf("%s/%d", feedID, changelog.To.UnixNano()),
+			Title:   fmt.Sprintf("%d catalog changes", len(changelog.Changes)),
+			Updated: changelog.To.UTC().Format(time.RFC3339),
+			Content: atomContent{Type: "text", Body: strings.Join(lines, "\n")},
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	w.Write([]byte(xml.Header))
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(feed); err != nil {
+		log.Printf("Error encoding Atom feed: %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"groupie_tracker/catalog"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// parseIDs reads a comma separated list of artist IDs, dropping duplicates
+func parseIDs(s string) ([]int, error) {
+	if s == "" {
+		return nil, errors.New("missing artist IDs")
+	}
+
+	var ids []int
+	for _, part := range strings.Split(s, ",") {
+		id, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || id < 1 {
+			return nil, fmt.Errorf("invalid artist ID %q", part)
+		}
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+
+	if len(ids) < 2 || len(ids) > catalog.MaxCompared {
+		return nil, fmt.Errorf("pick between 2 and %d different artists", catalog.MaxCompared)
+	}
+	return ids, nil
+}
+
+// loadComparison validates ?ids= and builds the comparison, rendering
+// the error itself when it fails
+func loadComparison(w http.ResponseWriter, r *http.Request) (catalog.Comparison, bool) {
+	// Accept both ?ids=1,5 and the ?ids=1&ids=5 a checkbox form submits
+	ids, err := parseIDs(strings.Join(r.URL.Query()["ids"], ","))
+	if err != nil {
+		log.Printf("Invalid comparison %q: %v", r.URL.RawQuery, err)
+		RenderError(w, r, http.StatusBadRequest, "Pick 2 to 4 different artists to compare")
+		return catalog.Comparison{}, false
+	}
+
+	cat, err := store.Current()
+	if err != nil {
+		log.Printf("Error loading catalog: %v", err)
+		RenderError(w, r, http.StatusServiceUnavailable, "Artist data is not available yet")
+		return catalog.Comparison{}, false
+	}
+
+	for _, id := range ids {
+		if _, found := cat.Artist(id); !found {
+			RenderError(w, r, http.StatusNotFound, "Artist not found")
+			return catalog.Comparison{}, false
+		}
+	}
+
+	cmp, err := cat.Compare(ids)
+	if err != nil {
+		log.Printf("Error comparing artists: %v", err)
+		RenderError(w, r, http.StatusInternalServerError, "Failed to compare artists")
+		return catalog.Comparison{}, false
+	}
+	return cmp, true
+}
+
+// CompareHandler displays up to four artists side by side
+func CompareHandler(w http.ResponseWriter, r *http.Request) {
+	cmp, ok := loadComparison(w, r)
+	if !ok {
+		return
+	}
+
+	renderTemplate(w, r, "compare.html", cmp)
+}
+
+// CompareAPIHandler is the JSON variant of CompareHandler
+func CompareAPIHandler(w http.ResponseWriter, r *http.Request) {
+	cmp, ok := loadComparison(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, cmp)
+}
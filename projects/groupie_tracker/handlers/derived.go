@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"groupie_tracker/catalog"
+	"sync"
+)
+
+// derivedCache is a cache of something computed from the catalog, such as
+// the search index. Caches are filled by PrecomputeDerived after every
+// refresh, and on the first request that needs them otherwise.
+type derivedCache interface {
+	Name() string
+	Built(cat *catalog.Catalog) bool
+	Fill(cat *catalog.Catalog)
+	Drop()
+}
+
+// derived caches the value built from the latest catalog it was asked for
+type derived[T any] struct {
+	name  string
+	build func(*catalog.Catalog) T
+
+	mu      sync.Mutex
+	catalog *catalog.Catalog
+	value   T
+}
+
+func newDerived[T any](name string, build func(*catalog.Catalog) T) *derived[T] {
+	return &derived[T]{name: name, build: build}
+}
+
+// Get returns the value of cat, building it if the cache holds another
+// catalog's
+func (d *derived[T]) Get(cat *catalog.Catalog) T {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.catalog != cat {
+		d.value = d.build(cat)
+		d.catalog = cat
+	}
+	return d.value
+}
+
+func (d *derived[T]) Name() string { return d.name }
+
+func (d *derived[T]) Fill(cat *catalog.Catalog) { d.Get(cat) }
+
+// Built tells whether the cache holds the value of cat
+func (d *derived[T]) Built(cat *catalog.Catalog) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.catalog == cat
+}
+
+// Drop forgets the value, so it is rebuilt on the next Get
+func (d *derived[T]) Drop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var zero T
+	d.catalog = nil
+	d.value = zero
+}
+
+// derivedCaches lists every cache derived from the catalog, in the order
+// they are precomputed and shown on the admin page
+var derivedCaches = []derivedCache{searchIndex, catalogStats, recommendations, nearIndex}
+
+// PrecomputeDerived builds every derived cache for a new catalog, so no
+// visitor waits for them. It is meant as a catalog.Store refresh listener.
+func PrecomputeDerived(cat *catalog.Catalog, _ catalog.Changelog) {
+	for _, c := range derivedCaches {
+		c.Fill(cat)
+	}
+}
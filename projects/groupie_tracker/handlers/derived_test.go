@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"groupie_tracker/catalog"
+)
+
+func TestDerivedCache(t *testing.T) {
+	builds := 0
+	d := newDerived("Artist count", func(cat *catalog.Catalog) int {
+		builds++
+		return len(cat.Artists)
+	})
+
+	first, second := testCatalog(time.Time{}), testCatalog(time.Time{})
+	if d.Built(first) {
+		t.Error("Expected an empty cache not to be built")
+	}
+	if d.Get(first) != 2 || d.Get(first) != 2 || builds != 1 {
+		t.Errorf("Expected one build for two reads of a catalog, got %d", builds)
+	}
+	if d.Get(second); builds != 2 || d.Built(first) || !d.Built(second) {
+		t.Errorf("Expected a new catalog to replace the cached value, got %d builds", builds)
+	}
+
+	d.Drop()
+	if d.Built(second) {
+		t.Error("Expected a dropped cache not to be built")
+	}
+	if d.Get(second); builds != 3 {
+		t.Errorf("Expected a dropped cache to be rebuilt, got %d builds", builds)
+	}
+}
+
+func TestPrecomputeDerived(t *testing.T) {
+	t.Cleanup(dropCaches)
+	cat := testCatalog(time.Time{})
+	PrecomputeDerived(cat, catalog.Changelog{})
+	for _, c := range adminCaches(cat) {
+		if !c.Built {
+			t.Errorf("Expected %s to be built after a refresh", c.Name)
+		}
+	}
+}
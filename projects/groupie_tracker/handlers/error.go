@@ -1,9 +1,11 @@
 package handlers
 
 import (
-	"html/template"
+	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
+	"strings"
 )
 
 type ErrorData struct {
@@ -12,30 +14,122 @@ type ErrorData struct {
 	Message    string
 }
 
-func RenderError(w http.ResponseWriter, statusCode int, message string) {
-	// 1. Set the HTTP response status code in the header
-	w.WriteHeader(statusCode)
+// Problem is an RFC 9457 problem details object
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+}
+
+// Media types RenderError can answer with
+const (
+	mediaHTML    = "text/html"
+	mediaProblem = "application/problem+json"
+	mediaJSON    = "application/json"
+	mediaText    = "text/plain"
+)
+
+// RenderError is the single place error responses are written. It
+// negotiates on Accept: browsers get the themed error page, programmatic
+// clients get problem details JSON or plain text. Paths under /api/
+// default to JSON when the client does not say.
+// message is an English catalog key, see package i18n.
+func RenderError(w http.ResponseWriter, r *http.Request, statusCode int, message string) {
+	// 1. Pick the language and format before any header is sent
+	locale := localeFor(w, r)
 
-	// 2. Prepare the data for the template
-	// http.StatusText(404) returns "Not Found" automatically
+	offers := []string{mediaHTML, mediaProblem, mediaJSON, mediaText}
+	if strings.HasPrefix(requestPath(r), "/api/") {
+		offers = []string{mediaProblem, mediaJSON, mediaText, mediaHTML}
+	}
+	format := negotiate(r, offers...)
+	if format == "" {
+		// Nothing acceptable: an error is still better than a 406
+		format = mediaText
+	}
+
+	// 2. Prepare the data
+	// http.StatusText(404) returns "Not Found", which is also its catalog key
 	data := ErrorData{
 		StatusCode: statusCode,
-		StatusText: http.StatusText(statusCode),
-		Message:    message,
+		StatusText: locale.T(http.StatusText(statusCode)),
+		Message:    locale.T(message),
 	}
 
-	// 3. Parse the error template
-	tmpl, err := template.ParseFiles("./templates/error.html")
-	if err != nil {
-		// If the error template fails to load, fall back to a basic text error
-		log.Printf("Crititcal: Error template not found: %v", err)
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-		return
+	// Errors must never be cached as if they were the resource
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Add("Vary", "Accept")
+
+	switch format {
+	case mediaProblem, mediaJSON:
+		// 3a. RFC 9457 problem details
+		w.Header().Set("Content-Type", format+"; charset=utf-8")
+		w.WriteHeader(statusCode)
+		err := json.NewEncoder(w).Encode(Problem{
+			Type:     "about:blank",
+			Title:    data.StatusText,
+			Status:   statusCode,
+			Detail:   data.Message,
+			Instance: requestPath(r),
+		})
+		if err != nil {
+			log.Printf("Error encoding problem details: %v", err)
+		}
+
+	case mediaText:
+		// 3b. Plain text
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.WriteHeader(statusCode)
+		fmt.Fprintf(w, "%d %s\n%s\n", statusCode, data.StatusText, data.Message)
+
+	default:
+		// 3c. Themed HTML page
+		tmpl, err := parseTemplate(r, locale, "error.html")
+		if err != nil {
+			// If the error template fails to load, fall back to a basic text error
+			log.Printf("Crititcal: Error template not found: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(statusCode)
+		err = tmpl.Execute(w, data)
+		if err != nil {
+			log.Printf("Error executing error template: %v", err)
+		}
 	}
+}
 
-	// 4. Execute the template with ErrorData
-	err = tmpl.Execute(w, data)
-	if err != nil {
-		log.Printf("Error executing error template: %v", err)
+// requestPath is the path the client asked for, even below http.StripPrefix
+func requestPath(r *http.Request) string {
+	if r.RequestURI == "" {
+		return r.URL.Path
 	}
+	p, _, _ := strings.Cut(r.RequestURI, "?")
+	return p
+}
+
+// NotFoundHandler renders the themed 404 for anything no route matched
+func NotFoundHandler(w http.ResponseWriter, r *http.Request) {
+	RenderError(w, r, http.StatusNotFound, "Page not found")
+}
+
+// Recover turns a panicking handler into a 500 rendered by RenderError
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if v := recover(); v != nil {
+				if v == http.ErrAbortHandler {
+					panic(v)
+				}
+				log.Printf("Panic serving %s: %v", r.URL.Path, v)
+				RenderError(w, r, http.StatusInternalServerError, "Internal Server Error")
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
 }
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNotFoundHandler(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/nothing", nil)
+	rec := httptest.NewRecorder()
+	NotFoundHandler(rec, req)
+
+	if rec.Code != http.StatusNotFound || !strings.Contains(rec.Body.String(), `"detail":"Page not found"`) {
+		t.Errorf("Expected a 404 problem, got %d %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Cache-Control"); got != "no-store" {
+		t.Errorf("Expected errors not to be cached, got %q", got)
+	}
+}
+
+func TestRecover(t *testing.T) {
+	panicking := Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	for accept, want := range map[string]string{
+		"application/problem+json": `"status":500`,
+		"text/plain":               "500 Internal Server Error",
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/artist?id=1", nil)
+		req.Header.Set("Accept", accept)
+		rec := httptest.NewRecorder()
+		panicking.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusInternalServerError || !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("%s: expected a 500 containing %q, got %d %s", accept, want, rec.Code, rec.Body.String())
+		}
+	}
+
+	// http.ErrAbortHandler must still abort the response
+	defer func() {
+		if v := recover(); v != http.ErrAbortHandler {
+			t.Errorf("Expected http.ErrAbortHandler to be re-panicked, got %v", v)
+		}
+	}()
+	aborting := Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	aborting.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"fmt"
+	"groupie_tracker/catalog"
+	"groupie_tracker/events"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// hub broadcasts catalog refreshes to the browsers on /events
+var hub *events.Hub
+
+// SetEvents wires the event hub used by EventsHandler and PublishRefresh
+func SetEvents(h *events.Hub) {
+	hub = h
+}
+
+// heartbeatInterval keeps idle connections from being cut by proxies
+var heartbeatInterval = 25 * time.Second
+
+// reconnectDelay is how long browsers wait before reconnecting
+const reconnectDelay = 5 * time.Second
+
+// RefreshEvent is published after every successful catalog refresh
+type RefreshEvent struct {
+	FetchedAt time.Time `json:"fetchedAt"`
+	Artists   int       `json:"artists"`
+	Changes   int       `json:"changes"`
+}
+
+// PublishRefresh announces a catalog refresh, and its changelog when
+// something changed. It is registered with catalog.Store.OnRefresh.
+func PublishRefresh(cat *catalog.Catalog, changelog catalog.Changelog) {
+	err := hub.Publish("refresh", RefreshEvent{
+		FetchedAt: cat.FetchedAt,
+		Artists:   len(cat.Artists),
+		Changes:   len(changelog.Changes),
+	})
+	if err == nil && len(changelog.Changes) > 0 {
+		err = hub.Publish("change", changelog)
+	}
+	if err != nil {
+		log.Printf("Error publishing refresh event: %v", err)
+	}
+}
+
+// EventsHandler streams refresh and change events as server-sent events
+// until the client goes away
+func EventsHandler(w http.ResponseWriter, r *http.Request) {
+	// 1. Subscribe, replaying what a reconnecting client missed
+	lastID, _ := strconv.ParseInt(r.Header.Get("Last-Event-ID"), 10, 64)
+	sub := hub.Subscribe(lastID)
+	defer hub.Unsubscribe(sub)
+
+	// 2. Start the stream
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	rc := http.NewResponseController(w)
+	fmt.Fprintf(w, "retry: %d\n\n", reconnectDelay.Milliseconds())
+	if err := rc.Flush(); err != nil {
+		log.Printf("Error starting event stream: %v", err)
+		return
+	}
+
+	// 3. Forward events, with a comment line as heartbeat when idle
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e, ok := <-sub.Events:
+			if !ok {
+				// Dropped for falling behind; the browser reconnects
+				return
+			}
+			if _, err := e.WriteTo(w); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"groupie_tracker/catalog"
+	"groupie_tracker/events"
+)
+
+// readEvent reads lines from an event stream up to the end of the next
+// event or comment
+func readEvent(t *testing.T, r *bufio.Reader) string {
+	t.Helper()
+	var lines []string
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Error reading event stream: %v", err)
+		}
+		if line == "\n" {
+			return strings.Join(lines, "")
+		}
+		lines = append(lines, line)
+	}
+}
+
+func TestEventsHandler(t *testing.T) {
+	setupCatalog(t)
+	h := events.NewHub(4)
+	SetEvents(h)
+	heartbeatInterval = 20 * time.Millisecond
+	t.Cleanup(func() { heartbeatInterval = 25 * time.Second })
+
+	// Through the middleware, which must not buffer the stream
+	srv := httptest.NewServer(Compress(http.HandlerFunc(EventsHandler)))
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ctype := resp.Header.Get("Content-Type"); ctype != "text/event-stream" || resp.Header.Get("Content-Encoding") != "" {
+		t.Fatalf("Expected an uncompressed event stream, got %s %q", ctype, resp.Header.Get("Content-Encoding"))
+	}
+	stream := bufio.NewReader(resp.Body)
+
+	if got := readEvent(t, stream); got != "retry: 5000\n" {
+		t.Errorf("Expected the reconnect delay first, got %q", got)
+	}
+	if got := readEvent(t, stream); got != ": ping\n" {
+		t.Errorf("Expected a heartbeat, got %q", got)
+	}
+
+	cat := testCatalog(time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC))
+	PublishRefresh(cat, catalog.Changelog{Changes: []catalog.Change{{Kind: catalog.ArtistAdded, ArtistID: 3, ArtistName: "SOJA"}}})
+
+	got := readEvent(t, stream)
+	for got == ": ping\n" {
+		got = readEvent(t, stream)
+	}
+	if !strings.HasPrefix(got, "id: 1\nevent: refresh\ndata: {\"fetchedAt\":\"2026-10-02T00:00:00Z\",\"artists\":2,\"changes\":1}") {
+		t.Errorf("Expected a refresh event, got %q", got)
+	}
+	if got := readEvent(t, stream); !strings.HasPrefix(got, "id: 2\nevent: change\n") || !strings.Contains(got, `"artistName":"SOJA"`) {
+		t.Errorf("Expected a change event, got %q", got)
+	}
+
+	// Disconnecting unsubscribes
+	resp.Body.Close()
+	deadline := time.Now().Add(time.Second)
+	for h.Len() != 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if h.Len() != 0 {
+		t.Errorf("Expected the client to be unsubscribed, %d left", h.Len())
+	}
+}
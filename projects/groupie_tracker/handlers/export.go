@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"fmt"
+	"groupie_tracker/export"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ExportHandler serves /export/{table}.csv, the catalog flattened into
+// spreadsheet tables. ?bom=1 prefixes a byte order mark for Excel.
+func ExportHandler(w http.ResponseWriter, r *http.Request) {
+	// 1. Validate the table and options
+	file := r.PathValue("file")
+	table, ok := strings.CutSuffix(file, ".csv")
+	if !ok || !slices.Contains(export.Tables, table) {
+		RenderError(w, r, http.StatusNotFound, "Page not found")
+		return
+	}
+
+	var opts export.Options
+	if v := r.URL.Query().Get("bom"); v != "" {
+		bom, err := strconv.ParseBool(v)
+		if err != nil {
+			RenderError(w, r, http.StatusBadRequest, "Invalid bom parameter")
+			return
+		}
+		opts.BOM = bom
+	}
+
+	cat, err := store.Current()
+	if err != nil {
+		log.Printf("Error loading catalog: %v", err)
+		RenderError(w, r, http.StatusServiceUnavailable, "Artist data is not available yet")
+		return
+	}
+
+	if checkNotModified(w, r, cat.FetchedAt, "export", table, opts.BOM) {
+		return
+	}
+
+	// 2. Send the table as a download
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8; header=present")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", file))
+	if err := export.Write(w, cat, table, opts); err != nil {
+		log.Printf("Error writing export: %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"groupie_tracker/catalog"
+	"groupie_tracker/favorites"
+	"groupie_tracker/models"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type FavoriteArtist struct {
+	Artist   models.Artist
+	Upcoming []catalog.Concert
+}
+
+type FavoritesData struct {
+	Artists   []FavoriteArtist
+	CSRFToken string
+	FeedToken string
+}
+
+// FavoritesHandler displays the starred artists of the session with their upcoming concerts
+func FavoritesHandler(w http.ResponseWriter, r *http.Request) {
+	// 1. Identify the visitor
+	sessionID := sessions.Ensure(w, r)
+
+	// 2. Load their favorites and the catalog
+	ids, err := favs.List(sessionID)
+	if err != nil {
+		log.Printf("Error listing favorites: %v", err)
+		RenderError(w, r, http.StatusInternalServerError, "Failed to load favorites")
+		return
+	}
+
+	cat, err := store.Current()
+	if err != nil {
+		log.Printf("Error loading catalog: %v", err)
+		RenderError(w, r, http.StatusServiceUnavailable, "Artist data is not available yet")
+		return
+	}
+
+	// 3. Resolve each ID, skipping artists that left the catalog
+	data := FavoritesData{
+		CSRFToken: sessions.CSRFToken(sessionID),
+		FeedToken: sessions.FeedToken(sessionID),
+	}
+	now := time.Now()
+	for _, id := range ids {
+		artist, ok := cat.Artist(id)
+		if !ok {
+			continue
+		}
+		data.Artists = append(data.Artists, FavoriteArtist{
+			Artist:   artist,
+			Upcoming: catalog.Upcoming(cat.Concerts(id), now),
+		})
+	}
+
+	// 4. Render favorites.html template
+	renderTemplate(w, r, "favorites.html", data)
+}
+
+// FavoriteAddHandler stars an artist for the session
+func FavoriteAddHandler(w http.ResponseWriter, r *http.Request) {
+	updateFavorite(w, r, favorites.Store.Add)
+}
+
+// FavoriteRemoveHandler unstars an artist for the session
+func FavoriteRemoveHandler(w http.ResponseWriter, r *http.Request) {
+	updateFavorite(w, r, favorites.Store.Remove)
+}
+
+func updateFavorite(w http.ResponseWriter, r *http.Request, update func(favorites.Store, string, int) error) {
+	// 1. Only accept form posts from pages we rendered
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		RenderError(w, r, http.StatusMethodNotAllowed, "Favorites can only be changed with POST")
+		return
+	}
+
+	sessionID, ok := sessions.ID(r)
+	if !ok || !sessions.ValidCSRF(sessionID, r.PostFormValue("csrf")) {
+		RenderError(w, r, http.StatusForbidden, "Invalid or missing CSRF token")
+		return
+	}
+
+	// 2. Validate the artist ID against the catalog
+	artistID, err := strconv.Atoi(r.PostFormValue("id"))
+	if err != nil || artistID < 1 {
+		RenderError(w, r, http.StatusBadRequest, "Invalid artist ID")
+		return
+	}
+
+	cat, err := store.Current()
+	if err != nil {
+		RenderError(w, r, http.StatusServiceUnavailable, "Artist data is not available yet")
+		return
+	}
+	if _, found := cat.Artist(artistID); !found {
+		RenderError(w, r, http.StatusNotFound, "Artist not found")
+		return
+	}
+
+	// 3. Apply the change
+	if err := update(favs, sessionID, artistID); err != nil {
+		log.Printf("Error updating favorites: %v", err)
+		RenderError(w, r, http.StatusInternalServerError, "Failed to update favorites")
+		return
+	}
+
+	// 4. Go back to where the form was posted from
+	http.Redirect(w, r, redirectTarget(r.PostFormValue("next")), http.StatusSeeOther)
+}
+
+// redirectTarget only allows local paths, so the form cannot be used as an open redirect
+func redirectTarget(next string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.Contains(next, `\`) {
+		return "/favorites"
+	}
+	return next
+}
@@ -0,0 +1,736 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"groupie_tracker/catalog"
+	"groupie_tracker/favorites"
+	"groupie_tracker/models"
+	"groupie_tracker/session"
+)
+
+// fakeCatalog is a CatalogSource serving a fixed catalog or error
+type fakeCatalog struct {
+	catalog *catalog.Catalog
+	err     error
+	changes []catalog.Changelog
+}
+
+func (f *fakeCatalog) Current() (*catalog.Catalog, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.catalog, nil
+}
+
+func (f *fakeCatalog) ChangesSince(since time.Time) []catalog.Changelog {
+	var result []catalog.Changelog
+	for _, changelog := range f.changes {
+		if changelog.To.After(since) {
+			result = append(result, changelog)
+		}
+	}
+	return result
+}
+
+// setupCatalog wires the handlers to a small fake catalog and the real templates
+func setupCatalog(t *testing.T) *fakeCatalog {
+	t.Helper()
+
+	templateDir = "../templates"
+	fake := &fakeCatalog{catalog: testCatalog(time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC))}
+	SetStore(fake)
+	SetFavorites(session.NewManager([]byte("test-secret")), favorites.NewMemoryStore())
+	return fake
+}
+
+func testCatalog(fetchedAt time.Time) *catalog.Catalog {
+	c := catalog.New(
+		[]models.Artist{
+			{ID: 1, Name: "Queen", Image: "https://example.com/queen.jpeg", Members: []string{"Freddie Mercury"}, CreationDate: 1970, FirstAlbum: "14-12-1973"},
+			{ID: 2, Name: "Pink Floyd", Image: "https://example.com/pinkfloyd.jpeg", Members: []string{"Roger Waters"}, CreationDate: 1965, FirstAlbum: "05-08-1967"},
+		},
+		[]models.Locations{{ID: 1, Locations: []string{"london-uk"}}, {ID: 2, Locations: []string{"paris-france"}}},
+		[]models.Dates{{ID: 1, Dates: []string{"*01-01-2020"}}, {ID: 2, Dates: []string{"02-02-2020"}}},
+		[]models.Relation{
+			{ID: 1, DatesLocations: map[string][]string{"london-uk": {"01-01-2020"}}},
+			{ID: 2, DatesLocations: map[string][]string{"paris-france": {"02-02-2020"}}},
+		},
+	)
+	c.FetchedAt = fetchedAt
+	return c
+}
+
+// brokenTemplates points templateDir at a copy of the templates in which
+// name does not parse
+func brokenTemplates(t *testing.T, name string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	entries, err := os.ReadDir("../templates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		data, err := os.ReadFile(filepath.Join("../templates", e.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e.Name() == name {
+			data = []byte("{{range}")
+		}
+		if err := os.WriteFile(filepath.Join(dir, e.Name()), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	templateDir = dir
+}
+
+func TestHomeHandler(t *testing.T) {
+	setupCatalog(t)
+
+	rec := get(HomeHandler, "/", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rec.Code)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{"<h2>Queen</h2>", "<h2>Pink Floyd</h2>", `href="/artist?id=2"`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected home page to contain %q", want)
+		}
+	}
+}
+
+func TestHomeHandlerUnknownPath(t *testing.T) {
+	setupCatalog(t)
+
+	rec := get(HomeHandler, "/no-such-page", nil)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "Page not found") {
+		t.Error("Expected the themed error page")
+	}
+}
+
+func TestHomeHandlerUpstreamFailure(t *testing.T) {
+	fake := setupCatalog(t)
+	fake.err = errors.New("upstream down")
+
+	rec := get(HomeHandler, "/", nil)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 when no catalog is available, got %d", rec.Code)
+	}
+}
+
+func TestHomeHandlerTemplateFailure(t *testing.T) {
+	setupCatalog(t)
+	brokenTemplates(t, "index.html")
+
+	rec := get(HomeHandler, "/", nil)
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("Expected 500 for a broken template, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "Template error") {
+		t.Error("Expected the error page to explain the template error")
+	}
+}
+
+func TestArtistHandler(t *testing.T) {
+	setupCatalog(t)
+
+	rec := get(ArtistHandler, "/artist?id=1", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rec.Code)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{`<h1 id="artist-name">Queen</h1>`, "Freddie Mercury", "London, UK", `<time datetime="2020-01-01">`, `name="csrf"`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected artist page to contain %q", want)
+		}
+	}
+	// Other artists only appear as recommendations
+	if strings.Contains(body, "Roger Waters") {
+		t.Error("Expected only the requested artist's details on the page")
+	}
+}
+
+func TestArtistHandlerErrors(t *testing.T) {
+	setupCatalog(t)
+
+	tests := []struct {
+		name   string
+		target string
+		code   int
+		detail string
+	}{
+		{"missing id", "/artist", http.StatusBadRequest, "Missing artist ID"},
+		{"non-numeric id", "/artist?id=abc", http.StatusBadRequest, "Invalid artist ID"},
+		{"zero id", "/artist?id=0", http.StatusBadRequest, "Invalid artist ID"},
+		{"negative id", "/artist?id=-3", http.StatusBadRequest, "Invalid artist ID"},
+		{"unknown id", "/artist?id=99", http.StatusNotFound, "Artist not found"},
+	}
+
+	for _, tt := range tests {
+		rec := get(ArtistHandler, tt.target, nil)
+		if rec.Code != tt.code {
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.code, rec.Code)
+		}
+		if !strings.Contains(rec.Body.String(), tt.detail) {
+			t.Errorf("%s: expected the error page to say %q", tt.name, tt.detail)
+		}
+	}
+}
+
+func TestArtistHandlerUpstreamFailure(t *testing.T) {
+	fake := setupCatalog(t)
+	fake.err = catalog.ErrNotLoaded
+
+	rec := get(ArtistHandler, "/artist?id=1", nil)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503, got %d", rec.Code)
+	}
+}
+
+func TestArtistHandlerTemplateFailure(t *testing.T) {
+	setupCatalog(t)
+	brokenTemplates(t, "artist.html")
+
+	rec := get(ArtistHandler, "/artist?id=1", nil)
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("Expected 500 for a broken template, got %d", rec.Code)
+	}
+}
+
+func TestSearchHandler(t *testing.T) {
+	setupCatalog(t)
+
+	tests := []struct {
+		query   string
+		want    []string
+		notWant []string
+	}{
+		{"queen", []string{"<h2>Queen</h2>"}, []string{"<h2>Pink Floyd</h2>"}},
+		{"roger", []string{"<h2>Pink Floyd</h2>", "<mark>Roger</mark> Waters"}, []string{"<h2>Queen</h2>"}},
+		{"paris", []string{"<h2>Pink Floyd</h2>", "<mark>Paris</mark>, France"}, []string{"<h2>Queen</h2>"}},
+		{"pink+floid", []string{"<h2>Pink Floyd</h2>", "<mark>Pink</mark> <mark>Floyd</mark>"}, []string{"<h2>Queen</h2>"}},
+		{"1970", []string{"<h2>Queen</h2>"}, []string{"<h2>Pink Floyd</h2>"}},
+		{"nobody", []string{"No artists match"}, []string{"<h2>Queen</h2>", "<h2>Pink Floyd</h2>"}},
+	}
+
+	for _, tt := range tests {
+		rec := get(SearchHandler, "/search?q="+tt.query, nil)
+		if rec.Code != http.StatusOK {
+			t.Errorf("%q: expected 200, got %d", tt.query, rec.Code)
+		}
+		body := rec.Body.String()
+		for _, want := range tt.want {
+			if !strings.Contains(body, want) {
+				t.Errorf("%q: expected results to contain %q", tt.query, want)
+			}
+		}
+		for _, notWant := range tt.notWant {
+			if strings.Contains(body, notWant) {
+				t.Errorf("%q: expected results not to contain %q", tt.query, notWant)
+			}
+		}
+	}
+}
+
+func TestSearchHandlerUpstreamFailure(t *testing.T) {
+	fake := setupCatalog(t)
+	fake.err = errors.New("upstream down")
+
+	rec := get(SearchHandler, "/search?q=queen", nil)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503, got %d", rec.Code)
+	}
+}
+
+func TestSearchAPIHandler(t *testing.T) {
+	setupCatalog(t)
+
+	rec := get(SearchAPIHandler, "/api/v1/search?q=qeen", nil)
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "application/json") {
+		t.Fatalf("Expected 200 JSON, got %d %s", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	body := rec.Body.String()
+	for _, want := range []string{`"artist":{"id":1,"name":"Queen"}`, `"field":"artist"`, `"highlights":[{"start":0,"end":5}]`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected response to contain %s, got %s", want, body)
+		}
+	}
+
+	rec = get(SearchAPIHandler, "/api/v1/search?q=queen&limit=0", nil)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid limit, got %d", rec.Code)
+	}
+}
+
+func TestMemberHandler(t *testing.T) {
+	setupCatalog(t)
+
+	member := func(slug string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/members/"+slug, nil)
+		req.SetPathValue("slug", slug)
+		rec := httptest.NewRecorder()
+		MemberHandler(rec, req)
+		return rec
+	}
+
+	rec := member("roger-waters")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rec.Code)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{"<h1>Roger Waters</h1>", `href="/artist?id=2"`, "Paris, France"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected member page to contain %q", want)
+		}
+	}
+
+	if rec := member("nobody"); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown member, got %d", rec.Code)
+	}
+
+	// Artist pages and member search results link to the member page
+	if !strings.Contains(get(ArtistHandler, "/artist?id=1", nil).Body.String(), `href="/members/freddie-mercury"`) {
+		t.Error("Expected the artist page to link its members")
+	}
+	if !strings.Contains(get(SearchHandler, "/search?q=waters", nil).Body.String(), `href="/members/roger-waters"`) {
+		t.Error("Expected member search results to link the member page")
+	}
+}
+
+func TestFavoriteHandlers(t *testing.T) {
+	setupCatalog(t)
+
+	// A visitor with a session and its CSRF token
+	cookie := get(ArtistHandler, "/artist?id=1", nil).Result().Cookies()[0]
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(cookie)
+	sessionID, _ := sessions.ID(req)
+	csrf := sessions.CSRFToken(sessionID)
+
+	post := func(h http.HandlerFunc, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/favorites/add", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(cookie)
+		rec := httptest.NewRecorder()
+		h(rec, req)
+		return rec
+	}
+
+	rec := post(FavoriteAddHandler, url.Values{"id": {"2"}, "csrf": {csrf}, "next": {"/artist?id=2"}})
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/artist?id=2" {
+		t.Errorf("Expected a redirect back to the artist, got %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+	if ids, _ := favs.List(sessionID); len(ids) != 1 || ids[0] != 2 {
+		t.Errorf("Expected artist 2 to be starred, got %v", ids)
+	}
+	post(FavoriteRemoveHandler, url.Values{"id": {"2"}, "csrf": {csrf}})
+	if ids, _ := favs.List(sessionID); len(ids) != 0 {
+		t.Errorf("Expected artist 2 to be unstarred, got %v", ids)
+	}
+
+	tests := []struct {
+		name string
+		form url.Values
+		want int
+	}{
+		{"missing CSRF token", url.Values{"id": {"1"}}, http.StatusForbidden},
+		{"wrong CSRF token", url.Values{"id": {"1"}, "csrf": {"forged"}}, http.StatusForbidden},
+		{"invalid ID", url.Values{"id": {"x"}, "csrf": {csrf}}, http.StatusBadRequest},
+		{"unknown artist", url.Values{"id": {"99"}, "csrf": {csrf}}, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if rec := post(FavoriteAddHandler, tt.form); rec.Code != tt.want {
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.want, rec.Code)
+		}
+	}
+	if ids, _ := favs.List(sessionID); len(ids) != 0 {
+		t.Errorf("Expected rejected requests not to star anything, got %v", ids)
+	}
+
+	rec = get(FavoriteAddHandler, "/favorites/add?id=1", map[string]string{"Cookie": cookie.String()})
+	if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != http.MethodPost {
+		t.Errorf("Expected 405 with Allow: POST, got %d %q", rec.Code, rec.Header().Get("Allow"))
+	}
+}
+
+func TestRedirectTarget(t *testing.T) {
+	for next, want := range map[string]string{
+		"/artist?id=1":           "/artist?id=1",
+		"":                       "/favorites",
+		"https://evil.example":   "/favorites",
+		"//evil.example/path":    "/favorites",
+		`/\evil.example`:         "/favorites",
+		"javascript:alert(1)":    "/favorites",
+		"artist?id=1":            "/favorites",
+		"/favorites?lang=ar#top": "/favorites?lang=ar#top",
+	} {
+		if got := redirectTarget(next); got != want {
+			t.Errorf("redirectTarget(%q) = %q, expected %q", next, got, want)
+		}
+	}
+}
+
+func TestStatsHandler(t *testing.T) {
+	setupCatalog(t)
+
+	rec := get(StatsHandler, "/stats", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rec.Code)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{"<dd>2</dd>", "Artists by formation decade", "1960s", "London, UK", `<svg class="chart"`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected stats page to contain %q", want)
+		}
+	}
+	if got := strings.Count(body, "<svg"); got != 5 {
+		t.Errorf("Expected 5 charts, got %d", got)
+	}
+
+	// Year and decade labels use the locale's digits, like the bar values
+	body = get(StatsHandler, "/stats", map[string]string{"Accept-Language": "ar"}).Body.String()
+	if !strings.Contains(body, "١٩٦٠") || strings.Contains(body, "1960") {
+		t.Error("Expected Arabic chart labels in Arabic-Indic digits")
+	}
+
+	// Aggregates are computed once per catalog
+	cat, _ := store.Current()
+	if first, second := statsFor(cat), statsFor(cat); &first.Decades[0] != &second.Decades[0] {
+		t.Error("Expected the stats of a catalog to be cached")
+	}
+}
+
+func TestExportHandler(t *testing.T) {
+	setupCatalog(t)
+
+	export := func(target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.SetPathValue("file", strings.TrimPrefix(req.URL.Path, "/export/"))
+		rec := httptest.NewRecorder()
+		ExportHandler(rec, req)
+		return rec
+	}
+
+	rec := export("/export/concerts.csv?bom=1")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Errorf("Expected a CSV content type, got %q", ct)
+	}
+	if cd := rec.Header().Get("Content-Disposition"); cd != `attachment; filename="concerts.csv"` {
+		t.Errorf("Expected a download named concerts.csv, got %q", cd)
+	}
+	if !strings.HasPrefix(rec.Body.String(), "\uFEFFartist_id,artist,location,city,country,date\r\n1,Queen,london-uk,London,UK,2020-01-01\r\n") {
+		t.Errorf("Unexpected export %q", rec.Body.String())
+	}
+
+	for target, want := range map[string]int{
+		"/export/venues.csv":          http.StatusNotFound,
+		"/export/artists":             http.StatusNotFound,
+		"/export/artists.csv?bom=yes": http.StatusBadRequest,
+	} {
+		if rec := export(target); rec.Code != want {
+			t.Errorf("%s: expected %d, got %d", target, want, rec.Code)
+		}
+	}
+}
+
+func TestSimilarArtists(t *testing.T) {
+	setupCatalog(t)
+
+	body := get(ArtistHandler, "/artist?id=1", nil).Body.String()
+	for _, want := range []string{"You might also like", `<a href="/artist?id=2">`, "Formed in the same era", "Similar line-up size"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected artist page to contain %q", want)
+		}
+	}
+
+	similar := func(id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/artists/"+id+"/similar", nil)
+		req.SetPathValue("id", id)
+		rec := httptest.NewRecorder()
+		SimilarAPIHandler(rec, req)
+		return rec
+	}
+
+	rec := similar("1")
+	var resp SimilarResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Expected JSON, got %q", rec.Body.String())
+	}
+	if resp.Artist.Name != "Queen" || len(resp.Similar) != 1 || resp.Similar[0].Artist.ID != 2 || resp.Weights != catalog.DefaultWeights {
+		t.Errorf("Unexpected response %+v", resp)
+	}
+
+	for id, want := range map[string]int{"x": http.StatusBadRequest, "99": http.StatusNotFound} {
+		if rec := similar(id); rec.Code != want {
+			t.Errorf("%s: expected %d, got %d", id, want, rec.Code)
+		}
+	}
+
+	// New weights take effect without a refresh
+	SetSimilarityWeights(catalog.Weights{Cities: 1})
+	t.Cleanup(func() { SetSimilarityWeights(catalog.DefaultWeights) })
+	if rec := similar("1"); !strings.Contains(rec.Body.String(), `"similar":[]`) {
+		t.Errorf("Expected no artists sharing a city, got %s", rec.Body.String())
+	}
+}
+
+func TestNearConcertsHandler(t *testing.T) {
+	setupCatalog(t)
+
+	rec := get(NearConcertsHandler, "/api/v1/concerts/near?lat=51.5074&lon=-0.1278&km=400", nil)
+	var resp NearResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Expected JSON, got %q", rec.Body.String())
+	}
+	if len(resp.Concerts) != 2 || resp.Concerts[0].Artist.Name != "Queen" || resp.Concerts[1].Place.Name != "Paris, France" {
+		t.Errorf("Expected London then Paris, got %+v", resp.Concerts)
+	}
+
+	// Paris is out of the default radius, and London out of the window
+	if body := get(NearConcertsHandler, "/api/v1/concerts/near?lat=51.5074&lon=-0.1278", nil).Body.String(); strings.Contains(body, "paris-france") {
+		t.Errorf("Expected only London within 100 km, got %s", body)
+	}
+	body := get(NearConcertsHandler, "/api/v1/concerts/near?lat=51.5074&lon=-0.1278&km=400&from=2020-02-02&to=2020-02-02", nil).Body.String()
+	if strings.Contains(body, "london-uk") || !strings.Contains(body, "paris-france") {
+		t.Errorf("Expected only the Paris concert, got %s", body)
+	}
+
+	for _, query := range []string{
+		"lon=0",
+		"lat=91&lon=0",
+		"lat=0&lon=abc",
+		"lat=0&lon=0&km=0",
+		"lat=0&lon=0&km=30000",
+		"lat=0&lon=0&from=01-02-2020",
+		"lat=0&lon=0&from=2020-03-01&to=2020-02-01",
+	} {
+		if rec := get(NearConcertsHandler, "/api/v1/concerts/near?"+query, nil); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", query, rec.Code)
+		}
+	}
+}
+
+func TestChangesFeedHandler(t *testing.T) {
+	fake := setupCatalog(t)
+	fake.changes = []catalog.Changelog{{
+		From:    time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC),
+		To:      time.Date(2026, 10, 1, 10, 30, 0, 0, time.UTC),
+		Changes: []catalog.Change{{Kind: catalog.ArtistAdded, ArtistID: 3, ArtistName: "SOJA"}},
+	}}
+
+	feed := func(host string) string {
+		req := httptest.NewRequest(http.MethodGet, "/changes.atom", nil)
+		req.Host = host
+		rec := httptest.NewRecorder()
+		ChangesFeedHandler(rec, req)
+		return rec.Body.String()
+	}
+
+	body := feed("localhost:8080")
+	for _, want := range []string{
+		"<id>tag:groupie-tracker,2026:changes</id>",
+		"<author>\n    <name>Groupie Tracker</name>",
+		`<link href="/changes.atom" rel="self"></link>`,
+		"<updated>2026-10-01T10:30:00Z</updated>",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected feed to contain %q, got %s", want, body)
+		}
+	}
+
+	// IDs stay the same whatever host the feed is fetched through
+	if !strings.Contains(body, "<id>tag:groupie-tracker,2026:changes/") || feed("groupie.example.com") != body {
+		t.Errorf("Expected the feed not to depend on the Host header, got %s", body)
+	}
+}
+
+func TestChangesHandler(t *testing.T) {
+	fake := setupCatalog(t)
+
+	changes := func(query string) (*httptest.ResponseRecorder, ChangesResponse) {
+		rec := get(ChangesHandler, "/api/v1/changes"+query, nil)
+		var resp ChangesResponse
+		if rec.Code == http.StatusOK {
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("Expected JSON, got %q: %v", rec.Body.String(), err)
+			}
+		}
+		return rec, resp
+	}
+
+	// Nothing changed yet: an empty list, not null
+	if rec, _ := changes(""); !strings.Contains(rec.Body.String(), `"changelogs":[]`) {
+		t.Errorf("Expected an empty list of changelogs, got %s", rec.Body.String())
+	}
+
+	fake.changes = []catalog.Changelog{
+		{
+			From:    time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC),
+			To:      time.Date(2026, 10, 1, 10, 30, 0, 0, time.UTC),
+			Changes: []catalog.Change{{Kind: catalog.ArtistAdded, ArtistID: 3, ArtistName: "SOJA"}},
+		},
+		{
+			From:    time.Date(2026, 10, 1, 10, 30, 0, 0, time.UTC),
+			To:      time.Date(2026, 10, 1, 11, 0, 0, 0, time.UTC),
+			Changes: []catalog.Change{{Kind: catalog.ArtistRemoved, ArtistID: 3, ArtistName: "SOJA"}},
+		},
+	}
+	rec, resp := changes("?since=2026-10-01T10:30:00Z")
+	if rec.Code != http.StatusOK || len(resp.Changelogs) != 1 || resp.Changelogs[0].Changes[0].Kind != catalog.ArtistRemoved {
+		t.Errorf("Expected the changelog after since, got %d %+v", rec.Code, resp.Changelogs)
+	}
+	if !resp.Since.Equal(time.Date(2026, 10, 1, 10, 30, 0, 0, time.UTC)) {
+		t.Errorf("Expected since to be echoed, got %v", resp.Since)
+	}
+	if _, resp := changes(""); len(resp.Changelogs) != 2 {
+		t.Errorf("Expected every changelog without since, got %d", len(resp.Changelogs))
+	}
+	if _, resp := changes("?since=2026-10-01T12:00:00%2B01:00"); len(resp.Changelogs) != 0 {
+		t.Errorf("Expected no changelog after 11:00 UTC, got %d", len(resp.Changelogs))
+	}
+
+	for _, since := range []string{"yesterday", "2026-10-01", "1727776800"} {
+		if rec, _ := changes("?since=" + since); rec.Code != http.StatusBadRequest {
+			t.Errorf("since=%s: expected 400, got %d", since, rec.Code)
+		}
+	}
+}
+
+func TestCompareAPIHandler(t *testing.T) {
+	setupCatalog(t)
+
+	rec := get(CompareAPIHandler, "/api/v1/compare?ids=2,1", nil)
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "application/json") {
+		t.Fatalf("Expected JSON, got %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	var shape map[string]json.RawMessage
+	if err := json.Unmarshal(rec.Body.Bytes(), &shape); err != nil {
+		t.Fatalf("Expected a JSON object, got %q: %v", rec.Body.String(), err)
+	}
+	for _, key := range []string{"artists", "sharedLocations", "sameDays", "tourOverlaps"} {
+		if got := string(shape[key]); got == "" || got == "null" {
+			t.Errorf("Expected %s to be a list, got %q", key, got)
+		}
+	}
+	var cmp catalog.Comparison
+	json.Unmarshal(rec.Body.Bytes(), &cmp)
+	if len(cmp.Artists) != 2 || cmp.Artists[0].Artist.Name != "Pink Floyd" {
+		t.Errorf("Expected Pink Floyd then Queen, got %+v", cmp.Artists)
+	}
+
+	// The checkbox form sends ids once per artist
+	if rec := get(CompareAPIHandler, "/api/v1/compare?ids=1&ids=2", nil); rec.Code != http.StatusOK {
+		t.Errorf("Expected repeated ids to be accepted, got %d", rec.Code)
+	}
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"", http.StatusBadRequest},
+		{"?ids=1", http.StatusBadRequest},
+		{"?ids=1,1", http.StatusBadRequest},
+		{"?ids=1,2,3,4,5", http.StatusBadRequest},
+		{"?ids=1,x", http.StatusBadRequest},
+		{"?ids=1,99", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		rec := get(CompareAPIHandler, "/api/v1/compare"+tt.query, nil)
+		if rec.Code != tt.want || !strings.HasPrefix(rec.Header().Get("Content-Type"), "application/problem+json") {
+			t.Errorf("%q: expected %d as problem+json, got %d %q", tt.query, tt.want, rec.Code, rec.Header().Get("Content-Type"))
+		}
+	}
+}
+
+func TestRenderErrorNegotiation(t *testing.T) {
+	setupCatalog(t)
+
+	tests := []struct {
+		target string
+		accept string
+		ctype  string
+		body   string
+	}{
+		{"/artist", "", "text/html", "<h1>404</h1>"},
+		{"/artist", "text/html,application/xhtml+xml,*/*;q=0.8", "text/html", "Artist not found"},
+		{"/artist", "application/json", "application/json", `"status":404`},
+		{"/artist", "application/problem+json", "application/problem+json", `"detail":"Artist not found"`},
+		{"/artist", "text/plain", "text/plain", "404 Not Found"},
+		{"/api/v1/compare", "", "application/problem+json", `"instance":"/api/v1/compare"`},
+		{"/api/v1/compare", "*/*", "application/problem+json", `"title":"Not Found"`},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+		if tt.accept != "" {
+			req.Header.Set("Accept", tt.accept)
+		}
+		rec := httptest.NewRecorder()
+		RenderError(rec, req, http.StatusNotFound, "Artist not found")
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("%s %q: expected 404, got %d", tt.target, tt.accept, rec.Code)
+		}
+		if ctype := rec.Header().Get("Content-Type"); !strings.HasPrefix(ctype, tt.ctype) {
+			t.Errorf("%s %q: expected %s, got %s", tt.target, tt.accept, tt.ctype, ctype)
+		}
+		if !strings.Contains(rec.Body.String(), tt.body) {
+			t.Errorf("%s %q: expected body to contain %q, got %s", tt.target, tt.accept, tt.body, rec.Body.String())
+		}
+	}
+}
+
+func TestRenderErrorTranslated(t *testing.T) {
+	setupCatalog(t)
+
+	rec := get(ArtistHandler, "/artist?id=99&lang=ar", nil)
+	body := rec.Body.String()
+	if !strings.Contains(body, `dir="rtl"`) || strings.Contains(body, "Artist not found") {
+		t.Errorf("Expected a translated right-to-left error page, got %s", body)
+	}
+}
+
+func TestRenderErrorMissingTemplate(t *testing.T) {
+	setupCatalog(t)
+	templateDir = t.TempDir()
+
+	rec := get(ArtistHandler, "/artist?id=99", nil)
+	if rec.Code != http.StatusInternalServerError || !strings.Contains(rec.Body.String(), "Internal Server Error") {
+		t.Errorf("Expected the plain text fallback, got %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestSuggestHandler(t *testing.T) {
+	setupCatalog(t)
+
+	rec := get(SuggestHandler, "/search/suggest?q=pi", nil)
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "application/json") {
+		t.Fatalf("Expected 200 JSON, got %d %s", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, `{"text":"Pink Floyd","type":"artist","label":"artist","url":"/artist?id=2"}`) {
+		t.Errorf("Expected Pink Floyd as a suggestion, got %s", body)
+	}
+
+	rec = get(SuggestHandler, "/search/suggest?q=", nil)
+	if !strings.Contains(rec.Body.String(), `"suggestions":[]`) {
+		t.Errorf("Expected an empty list for an empty query, got %s", rec.Body.String())
+	}
+}
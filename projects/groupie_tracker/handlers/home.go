@@ -1,8 +1,7 @@
 package handlers
 
 import (
-	"groupie_tracker/api"
-	"html/template"
+	"groupie_tracker/i18n"
 	"log"
 	"net/http"
 )
@@ -11,32 +10,25 @@ import (
 func HomeHandler(w http.ResponseWriter, r *http.Request) {
 	// 1. Check if path is exactly "/"
 	if r.URL.Path != "/" {
-		http.NotFound(w, r)
+		NotFoundHandler(w, r)
 		return
 	}
 
-	// 2. Fetch artists from API
-	artists, err := api.GetArtists()
+	// 2. Get artists from the catalog
+	cat, err := store.Current()
 	if err != nil {
 		// Log the actual error for the developer, send a generic one to the user
-		log.Printf("Error fetching artists: %v", err)
-		RenderError(w, http.StatusInternalServerError, "Internal Server Error")
+		log.Printf("Error loading catalog: %v", err)
+		RenderError(w, r, http.StatusServiceUnavailable, "Artist data is not available yet")
 		return
 	}
+	artists := cat.Artists
 
-	// 3. Parse HTML template
-	// Use a relative path that matches your project structure
-	tmpl, err := template.ParseFiles("./templates/index.html")
-	if err != nil {
-		log.Printf("Error parsing template: %v", err)
-		RenderError(w, http.StatusInternalServerError, "Template error")
+	// The page only changes with the catalog and the language
+	if checkNotModified(w, r, cat.FetchedAt, "index", i18n.Negotiate(r).Tag) {
 		return
 	}
 
-	// 4. Execute template with artists data
-	// Note: No need for &artists, passing the slice directly is standard
-	err = tmpl.Execute(w, artists)
-	if err != nil {
-		log.Printf("Error executing template: %v", err)
-	}
+	// 3. Render index.html template in the visitor's language
+	renderTemplate(w, r, "index.html", artists)
 }
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"bytes"
+	"groupie_tracker/imagecache"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// images caches the artist pictures. Without it pictures are loaded by
+// browsers straight from upstream.
+var images *imagecache.Cache
+
+// SetImages wires the artist picture cache used by ArtistImageHandler
+func SetImages(c *imagecache.Cache) {
+	images = c
+}
+
+// ArtistImageHandler serves /artists/{id}/image from the image cache.
+// Only pictures on the upstream host are fetched by the server; any other
+// picture URL is a redirect, so the server cannot be made to fetch
+// arbitrary URLs.
+func ArtistImageHandler(w http.ResponseWriter, r *http.Request) {
+	// 1. Validate the artist ID from the path
+	artistID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || artistID < 1 {
+		RenderError(w, r, http.StatusBadRequest, "Invalid artist ID")
+		return
+	}
+
+	cat, err := store.Current()
+	if err != nil {
+		log.Printf("Error loading catalog: %v", err)
+		RenderError(w, r, http.StatusServiceUnavailable, "Artist data is not available yet")
+		return
+	}
+
+	artist, found := cat.Artist(artistID)
+	if !found || artist.Image == "" {
+		RenderError(w, r, http.StatusNotFound, "Artist not found")
+		return
+	}
+
+	// 2. Pictures we do not cache are loaded from where they are
+	if images == nil || !upstreamURL(artist.Image) {
+		http.Redirect(w, r, artist.Image, http.StatusFound)
+		return
+	}
+
+	img, err := images.Get(artist.Image)
+	if err != nil {
+		log.Printf("Error fetching image of artist %d: %v", artistID, err)
+		RenderError(w, r, http.StatusBadGateway, "Artist image is not available")
+		return
+	}
+
+	// 3. Pictures rarely change; a refresh that changes one keeps its URL,
+	// so browsers revalidate after a day
+	w.Header().Set("Content-Type", img.ContentType)
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	w.Header().Set("ETag", img.ETag)
+	http.ServeContent(w, r, "", img.FetchedAt, bytes.NewReader(img.Data))
+}
+
+// upstreamURL reports whether link is on the upstream API host
+func upstreamURL(link string) bool {
+	u, err := url.Parse(link)
+	return err == nil && u.Scheme+"://"+u.Host == upstreamOrigin()
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"groupie_tracker/api"
+	"groupie_tracker/imagecache"
+)
+
+// roundTripFunc answers the image cache's requests without a network
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+// setupImages puts Queen's picture on the upstream host and caches
+// pictures from a fake upstream, counting its requests
+func setupImages(t *testing.T) (*fakeCatalog, *int) {
+	t.Helper()
+	fake := setupCatalog(t)
+	fake.catalog.Artists[0].Image = strings.TrimSuffix(api.BaseURL, "/api") + "/images/queen.jpeg"
+
+	fetches := 0
+	client := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		fetches++
+		rec := httptest.NewRecorder()
+		if r.URL.Path == "/images/queen.jpeg" {
+			rec.Header().Set("Content-Type", "image/jpeg")
+			rec.WriteString("jpeg data")
+		} else {
+			rec.WriteHeader(http.StatusNotFound)
+		}
+		return rec.Result(), nil
+	})}
+	SetImages(imagecache.New(client, 1<<20))
+	t.Cleanup(func() { SetImages(nil) })
+	return fake, &fetches
+}
+
+func image(id string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/artists/"+id+"/image", nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	req.SetPathValue("id", id)
+	rec := httptest.NewRecorder()
+	ArtistImageHandler(rec, req)
+	return rec
+}
+
+func TestArtistImageHandler(t *testing.T) {
+	_, fetches := setupImages(t)
+
+	rec := image("1", nil)
+	body, _ := io.ReadAll(rec.Body)
+	if rec.Code != http.StatusOK || string(body) != "jpeg data" || rec.Header().Get("Content-Type") != "image/jpeg" {
+		t.Fatalf("Expected the cached picture, got %d %q %q", rec.Code, rec.Header().Get("Content-Type"), body)
+	}
+	if got := rec.Header().Get("Cache-Control"); got != "public, max-age=86400" {
+		t.Errorf("Expected pictures to be cached for a day, got %q", got)
+	}
+
+	rec = image("1", map[string]string{"If-None-Match": rec.Header().Get("ETag")})
+	if rec.Code != http.StatusNotModified || *fetches != 1 {
+		t.Errorf("Expected 304 from the cache, got %d after %d fetches", rec.Code, *fetches)
+	}
+
+	// Pictures elsewhere are not fetched by the server
+	rec = image("2", nil)
+	if rec.Code != http.StatusFound || rec.Header().Get("Location") != "https://example.com/pinkfloyd.jpeg" || *fetches != 1 {
+		t.Errorf("Expected a redirect to the picture, got %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+
+	if rec := image("99", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown artist, got %d", rec.Code)
+	}
+	if rec := image("x", nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid ID, got %d", rec.Code)
+	}
+}
+
+func TestArtistImageHandlerUpstreamFailure(t *testing.T) {
+	fake, _ := setupImages(t)
+	fake.catalog.Artists[0].Image = strings.TrimSuffix(api.BaseURL, "/api") + "/images/gone.jpeg"
+
+	if rec := image("1", nil); rec.Code != http.StatusBadGateway {
+		t.Errorf("Expected 502 when upstream has no picture, got %d", rec.Code)
+	}
+}
+
+func TestArtistImageHandlerWithoutCache(t *testing.T) {
+	setupCatalog(t)
+
+	rec := image("1", nil)
+	if rec.Code != http.StatusFound || rec.Header().Get("Location") != "https://example.com/queen.jpeg" {
+		t.Errorf("Expected a redirect without an image cache, got %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"groupie_tracker/catalog"
+	"groupie_tracker/i18n"
+	"groupie_tracker/models"
+	"log"
+	"net/http"
+)
+
+type MemberData struct {
+	Member catalog.Member
+	Bands  []MemberBand
+}
+
+// MemberBand is one of the artists a member plays in, with its concerts
+type MemberBand struct {
+	Artist   models.Artist
+	Concerts []catalog.Concert
+}
+
+// MemberHandler lists every artist a member appears in and their concerts
+func MemberHandler(w http.ResponseWriter, r *http.Request) {
+	// 1. Get the catalog
+	cat, err := store.Current()
+	if err != nil {
+		log.Printf("Error loading catalog: %v", err)
+		RenderError(w, r, http.StatusServiceUnavailable, "Artist data is not available yet")
+		return
+	}
+
+	// 2. Find the member by slug
+	member, found := cat.Member(r.PathValue("slug"))
+	if !found {
+		RenderError(w, r, http.StatusNotFound, "Member not found")
+		return
+	}
+
+	// 3. Collect the member's bands and their concerts
+	data := MemberData{Member: member}
+	for _, ref := range member.Artists {
+		artist, _ := cat.Artist(ref.ID)
+		data.Bands = append(data.Bands, MemberBand{Artist: artist, Concerts: cat.Concerts(ref.ID)})
+	}
+
+	if checkNotModified(w, r, cat.FetchedAt, "member", member.Slug, i18n.Negotiate(r).Tag) {
+		return
+	}
+
+	// 4. Render member.html template
+	renderTemplate(w, r, "member.html", data)
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"groupie_tracker/metrics"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var (
+	requestsTotal = metrics.NewCounterVec(
+		"groupie_http_requests_total",
+		"HTTP requests by route and status code.",
+		"route", "code",
+	)
+	requestDuration = metrics.NewHistogramVec(
+		"groupie_http_request_duration_seconds",
+		"HTTP request latency by route.",
+		nil,
+		"route",
+	)
+)
+
+// statusRecorder remembers the status code written through it
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (rec *statusRecorder) WriteHeader(code int) {
+	if rec.status == 0 {
+		rec.status = code
+	}
+	rec.ResponseWriter.WriteHeader(code)
+}
+
+func (rec *statusRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	return rec.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the real writer
+func (rec *statusRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// Flush keeps streaming responses working through the recorder
+func (rec *statusRecorder) Flush() {
+	if f, ok := rec.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Instrument records request counts and latency per route of next. The
+// route is the pattern of mux that will serve the request, which keeps
+// the number of label values bounded whatever paths clients ask for.
+func Instrument(mux *http.ServeMux, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, route := mux.Handler(r)
+		if route == "" {
+			route = "unmatched"
+		}
+
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+		defer func() {
+			if rec.status == 0 {
+				rec.status = http.StatusOK
+			}
+			requestsTotal.With(route, strconv.Itoa(rec.status)).Inc()
+			requestDuration.With(route).ObserveSince(start)
+		}()
+
+		next.ServeHTTP(rec, r)
+	})
+}
+
+// MetricsHandler exposes every metric in the Prometheus text format
+func MetricsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	metrics.Default.WriteText(w)
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestInstrument(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /instrumented/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("id") == "missing" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("ok"))
+	})
+	mux.HandleFunc("GET /instrumented-silent", func(w http.ResponseWriter, r *http.Request) {})
+	h := Instrument(mux, mux)
+
+	// Counters are global, so compare with their values before the requests
+	series := []struct {
+		route, code string
+		requests    uint64
+	}{
+		{"GET /instrumented/{id}", "200", 2},
+		{"GET /instrumented/{id}", "404", 1},
+		{"GET /instrumented-silent", "200", 1}, // nothing written means 200
+		{"unmatched", "404", 1},
+	}
+	before := make([]uint64, len(series))
+	for i, s := range series {
+		before[i] = requestsTotal.With(s.route, s.code).Value()
+	}
+
+	for _, target := range []string{"/instrumented/1", "/instrumented/2", "/instrumented/missing", "/instrumented-silent", "/elsewhere/3"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+
+	rec := get(MetricsHandler, "/metrics", nil)
+	if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/plain; version=0.0.4") {
+		t.Errorf("Expected the Prometheus text format, got %q", got)
+	}
+	body := rec.Body.String()
+	for i, s := range series {
+		want := fmt.Sprintf("groupie_http_requests_total{route=%q,code=%q} %d\n", s.route, s.code, before[i]+s.requests)
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected metrics to contain %q", want)
+		}
+	}
+	for _, want := range []string{
+		"# TYPE groupie_http_requests_total counter\n",
+		"# TYPE groupie_http_request_duration_seconds histogram\n",
+		`groupie_http_request_duration_seconds_bucket{route="GET /instrumented/{id}",le="+Inf"} `,
+		`groupie_http_request_duration_seconds_count{route="GET /instrumented/{id}"} `,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected metrics to contain %q", want)
+		}
+	}
+
+	// Routes are patterns, so paths never become label values
+	if strings.Contains(body, "/instrumented/1") || strings.Contains(body, "/elsewhere/3") {
+		t.Error("Expected request paths not to become label values")
+	}
+}
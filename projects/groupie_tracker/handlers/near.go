@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"groupie_tracker/catalog"
+	"groupie_tracker/geo"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Radius limits of the nearby concerts API, in kilometres
+const (
+	defaultNearKm = 100
+	maxNearKm     = 20000 // half the circumference reaches everywhere
+)
+
+// nearIndex caches the spatial index of the latest catalog
+var nearIndex = newDerived("Nearby concerts index", func(cat *catalog.Catalog) *geo.Index {
+	index := geo.Build(cat)
+	if missing := index.Unresolved(); len(missing) > 0 {
+		log.Printf("No coordinates for %d locations, left out of nearby searches: %v", len(missing), missing)
+	}
+	return index
+})
+
+func geoIndexFor(cat *catalog.Catalog) *geo.Index {
+	return nearIndex.Get(cat)
+}
+
+type NearResponse struct {
+	Center   geo.Point   `json:"center"`
+	Km       float64     `json:"km"`
+	From     string      `json:"from,omitempty"`
+	To       string      `json:"to,omitempty"`
+	Concerts []geo.Match `json:"concerts"`
+}
+
+// NearConcertsHandler returns the concerts within ?km= (default 100) of
+// ?lat= and ?lon=, nearest first. ?from= and ?to= (YYYY-MM-DD) narrow
+// the dates.
+func NearConcertsHandler(w http.ResponseWriter, r *http.Request) {
+	// 1. Validate the query
+	query := r.URL.Query()
+	lat, latErr := strconv.ParseFloat(query.Get("lat"), 64)
+	lon, lonErr := strconv.ParseFloat(query.Get("lon"), 64)
+	center := geo.Point{Lat: lat, Lon: lon}
+	if latErr != nil || lonErr != nil || !center.Valid() {
+		RenderError(w, r, http.StatusBadRequest, "Invalid lat or lon parameter")
+		return
+	}
+
+	km := float64(defaultNearKm)
+	if kmStr := query.Get("km"); kmStr != "" {
+		var err error
+		km, err = strconv.ParseFloat(kmStr, 64)
+		if err != nil || math.IsNaN(km) || km <= 0 || km > maxNearKm {
+			RenderError(w, r, http.StatusBadRequest, "Invalid km parameter")
+			return
+		}
+	}
+
+	from, fromErr := parseDay(query.Get("from"))
+	to, toErr := parseDay(query.Get("to"))
+	if fromErr != nil || toErr != nil || (!from.IsZero() && !to.IsZero() && to.Before(from)) {
+		RenderError(w, r, http.StatusBadRequest, "Invalid from or to parameter, expected YYYY-MM-DD")
+		return
+	}
+
+	// 2. Search the catalog
+	cat, err := store.Current()
+	if err != nil {
+		log.Printf("Error loading catalog: %v", err)
+		RenderError(w, r, http.StatusServiceUnavailable, "Artist data is not available yet")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, NearResponse{
+		Center:   center,
+		Km:       km,
+		From:     query.Get("from"),
+		To:       query.Get("to"),
+		Concerts: geoIndexFor(cat).Near(center, km, from, to),
+	})
+}
+
+// parseDay reads an optional YYYY-MM-DD date; empty is the zero time
+func parseDay(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.DateOnly, s)
+}
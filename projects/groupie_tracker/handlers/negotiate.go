@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// negotiate picks the offered media type the client accepts most, using
+// the q-values of the Accept header. The first offer wins ties and is
+// the default when the header is missing. It returns "" if the client
+// accepts none of the offers.
+func negotiate(r *http.Request, offers ...string) string {
+	header := r.Header.Get("Accept")
+	if header == "" {
+		return offers[0]
+	}
+
+	best, bestQ, bestSpecificity := "", 0.0, -1
+	for _, part := range strings.Split(header, ",") {
+		mediaRange, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		mediaRange = strings.ToLower(strings.TrimSpace(mediaRange))
+
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+
+		for _, offer := range offers {
+			specificity := matchMediaRange(mediaRange, offer)
+			if specificity < 0 {
+				continue
+			}
+			// Prefer higher q, then more specific ranges, then earlier offers
+			if q > bestQ || (q == bestQ && specificity > bestSpecificity) {
+				best, bestQ, bestSpecificity = offer, q, specificity
+			}
+			break
+		}
+	}
+	return best
+}
+
+// matchMediaRange reports how specifically mediaRange matches offer:
+// 2 for an exact match, 1 for type/*, 0 for */*, -1 for no match
+func matchMediaRange(mediaRange, offer string) int {
+	switch {
+	case mediaRange == offer:
+		return 2
+	case mediaRange == "*/*":
+		return 0
+	case strings.HasSuffix(mediaRange, "/*"):
+		offerType, _, _ := strings.Cut(offer, "/")
+		if strings.TrimSuffix(mediaRange, "/*") == offerType {
+			return 1
+		}
+	}
+	return -1
+}
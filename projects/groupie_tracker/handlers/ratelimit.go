@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"groupie_tracker/metrics"
+	"groupie_tracker/ratelimit"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// RateLimits holds one budget per kind of traffic. Pages, the JSON API
+// and search each fan out differently, so they are limited separately.
+type RateLimits struct {
+	HTML   *ratelimit.Limiter
+	API    *ratelimit.Limiter
+	Search *ratelimit.Limiter
+	// Suggest covers search-as-you-type, which sends a request at every
+	// pause in typing and needs a far larger burst than full searches
+	Suggest *ratelimit.Limiter
+
+	// TrustProxy reads the client IP from the last X-Forwarded-For
+	// entry. Only enable it behind a reverse proxy that appends to the
+	// header, or clients can pick their own bucket.
+	TrustProxy bool
+}
+
+var rateLimited = metrics.NewCounterVec(
+	"groupie_ratelimit_rejected_total",
+	"Requests rejected by the rate limiter.",
+	"budget",
+)
+
+// RateLimit rejects clients that exceed their budget with 429 Too Many Requests
+func RateLimit(limits RateLimits, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		budget, limiter := limits.budget(r.URL.Path)
+		if limiter == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ok, retryAfter := limiter.Allow(clientIP(r, limits.TrustProxy))
+		if !ok {
+			rateLimited.With(budget).Inc()
+			seconds := int(math.Ceil(retryAfter.Seconds()))
+			w.Header().Set("Retry-After", strconv.Itoa(max(seconds, 1)))
+			RenderError(w, r, http.StatusTooManyRequests, "Too many requests, please slow down")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// budget classifies a request path. Static files are cheap and not limited.
+func (limits RateLimits) budget(path string) (string, *ratelimit.Limiter) {
+	switch {
+	case strings.HasPrefix(path, "/static/"):
+		return "", nil
+	case strings.HasPrefix(path, "/artists/") && strings.HasSuffix(path, "/image"):
+		// Pages show dozens of pictures, served from the image cache
+		return "", nil
+	case path == "/search/suggest":
+		return "suggest", limits.Suggest
+	case path == "/search" || strings.HasPrefix(path, "/search/") || path == "/api/v1/search":
+		// Before /api/: the JSON search costs as much as the page
+		return "search", limits.Search
+	case strings.HasPrefix(path, "/api/"):
+		return "api", limits.API
+	default:
+		return "html", limits.HTML
+	}
+}
+
+// clientIP identifies the client a request is charged to
+func clientIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		// Proxies append the address they saw, so only the right-most
+		// entry comes from our proxy; anything before it is whatever
+		// the client sent
+		forwarded := strings.Join(r.Header.Values("X-Forwarded-For"), ",")
+		if i := strings.LastIndex(forwarded, ","); i >= 0 {
+			forwarded = forwarded[i+1:]
+		}
+		if last := strings.TrimSpace(forwarded); last != "" {
+			return last
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"groupie_tracker/ratelimit"
+)
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name       string
+		forwarded  []string
+		trustProxy bool
+		want       string
+	}{
+		{"direct", nil, false, "203.0.113.7"},
+		{"header ignored without a proxy", []string{"198.51.100.1"}, false, "203.0.113.7"},
+		{"set by the proxy", []string{"198.51.100.1"}, true, "198.51.100.1"},
+		{"spoofed left-most entry", []string{"10.9.8.7, 198.51.100.1"}, true, "198.51.100.1"},
+		{"spoofed header before the proxy's", []string{"10.9.8.7", "198.51.100.1"}, true, "198.51.100.1"},
+		{"empty header", []string{""}, true, "203.0.113.7"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "203.0.113.7:52000"
+		for _, v := range tt.forwarded {
+			req.Header.Add("X-Forwarded-For", v)
+		}
+		if got := clientIP(req, tt.trustProxy); got != tt.want {
+			t.Errorf("%s: clientIP = %q, expected %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// A client making up a new X-Forwarded-For entry on every request must
+// still run out of budget
+func TestRateLimitIgnoresSpoofedForwarding(t *testing.T) {
+	setupCatalog(t)
+	limits := RateLimits{HTML: ratelimit.New(1, 3), TrustProxy: true}
+	handler := RateLimit(limits, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	codes := make([]int, 5)
+	for i := range codes {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-Forwarded-For", fmt.Sprintf("10.0.0.%d, 198.51.100.1", i))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		codes[i] = rec.Code
+	}
+	if codes[2] != http.StatusOK || codes[3] != http.StatusTooManyRequests {
+		t.Errorf("Expected the burst of 3 to be shared, got %v", codes)
+	}
+}
+
+// Typing "pink floyd" with a pause after every key sends ten suggestion
+// requests; with main.go's default budgets none may be rejected
+func TestRateLimitLetsSuggestionsThrough(t *testing.T) {
+	setupCatalog(t)
+	limits := RateLimits{
+		Search:  ratelimit.New(2, 9),
+		Suggest: ratelimit.New(10, 41),
+	}
+	handler := RateLimit(limits, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	query := "pink floyd"
+	for i := 1; i <= len(query); i++ {
+		req := httptest.NewRequest(http.MethodGet, "/search/suggest?q="+url.QueryEscape(query[:i]), nil)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Suggestion %d of %q was rejected with %d", i, query, rec.Code)
+		}
+	}
+
+	// Suggestions do not eat into the budget of full searches
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/search?q=pink+floyd", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected the search itself to be allowed, got %d", rec.Code)
+	}
+}
+
+func TestRateLimitBudgets(t *testing.T) {
+	limits := RateLimits{
+		HTML:    ratelimit.New(1, 1),
+		API:     ratelimit.New(1, 1),
+		Search:  ratelimit.New(1, 1),
+		Suggest: ratelimit.New(1, 1),
+	}
+	for path, want := range map[string]string{
+		"/":                       "html",
+		"/artist":                 "html",
+		"/static/css/style.css":   "",
+		"/artists/1/image":        "",
+		"/artists/1/concerts.ics": "html",
+		"/search":                 "search",
+		"/search/suggest":         "suggest",
+		"/api/v1/search":          "search",
+		"/api/v1/compare":         "api",
+		"/api/v1/artists/1":       "api",
+		"/searching":              "html",
+	} {
+		if got, _ := limits.budget(path); got != want {
+			t.Errorf("budget(%q) = %q, expected %q", path, got, want)
+		}
+	}
+}
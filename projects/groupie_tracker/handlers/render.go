@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"groupie_tracker/catalog"
+	"groupie_tracker/i18n"
+	"html/template"
+	"log"
+	"net/http"
+	"path/filepath"
+	"time"
+)
+
+// localeFor negotiates the request's locale and remembers an explicit choice
+func localeFor(w http.ResponseWriter, r *http.Request) *i18n.Locale {
+	locale := i18n.Negotiate(r)
+	i18n.Remember(w, r, locale)
+	w.Header().Set("Content-Language", locale.Tag)
+	return locale
+}
+
+// templateFuncs are available in every page template
+func templateFuncs(r *http.Request, locale *i18n.Locale) template.FuncMap {
+	return template.FuncMap{
+		"t":    locale.T,
+		"num":  locale.Number,
+		"lang": func() string { return locale.Tag },
+		"dir":  func() string { return locale.Dir },
+		// date accepts a time.Time, *time.Time or an upstream "dd-mm-yyyy" string
+		"date": func(v any) string {
+			if d, ok := asTime(v); ok {
+				return locale.FormatDate(d)
+			}
+			if s, ok := v.(string); ok {
+				return s
+			}
+			return ""
+		},
+		// datetime formats the same values for <time datetime="...">
+		"datetime": func(v any) string {
+			if d, ok := asTime(v); ok {
+				return d.Format(time.DateOnly)
+			}
+			return ""
+		},
+		"place":   catalog.FormatLocation,
+		"slug":    catalog.Slug,
+		"locales": i18n.Supported,
+		"asset":   manifest.URL,
+		// langURL links to the current page in another language
+		"langURL": func(tag string) string {
+			u := *r.URL
+			q := u.Query()
+			q.Set("lang", tag)
+			u.RawQuery = q.Encode()
+			return u.RequestURI()
+		},
+	}
+}
+
+// asTime converts the date values templates deal with
+func asTime(v any) (time.Time, bool) {
+	switch d := v.(type) {
+	case time.Time:
+		return d, true
+	case *time.Time:
+		if d != nil {
+			return *d, true
+		}
+	case string:
+		if parsed, err := catalog.ParseDate(d); err == nil {
+			return parsed, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// templateDir is where page templates are loaded from, relative to the
+// working directory
+var templateDir = "./templates"
+
+// parseTemplate loads a page template with the locale's helpers
+func parseTemplate(r *http.Request, locale *i18n.Locale, name string) (*template.Template, error) {
+	return template.New(name).Funcs(templateFuncs(r, locale)).ParseFiles(filepath.Join(templateDir, name))
+}
+
+// renderTemplate renders a page template in the request's locale
+func renderTemplate(w http.ResponseWriter, r *http.Request, name string, data any) {
+	locale := localeFor(w, r)
+
+	tmpl, err := parseTemplate(r, locale, name)
+	if err != nil {
+		log.Printf("Error parsing template: %v", err)
+		RenderError(w, r, http.StatusInternalServerError, "Template error")
+		return
+	}
+
+	err = tmpl.Execute(w, data)
+	if err != nil {
+		log.Printf("Error executing template: %v", err)
+	}
+}
@@ -1 +1,113 @@
 package handlers
+
+import (
+	"groupie_tracker/catalog"
+	"groupie_tracker/models"
+	"groupie_tracker/search"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// Result limits for the search page and the JSON API
+const (
+	maxSearchResults    = 50
+	maxAPISearchResults = 100
+)
+
+// searchIndex caches the index of the latest catalog
+var searchIndex = newDerived("Search index", search.Build)
+
+func indexFor(cat *catalog.Catalog) *search.Index {
+	return searchIndex.Get(cat)
+}
+
+type SearchData struct {
+	Query   string
+	Results []SearchResult
+}
+
+// SearchResult is a search hit ready for search.html
+type SearchResult struct {
+	Artist    models.Artist
+	MatchType string
+	Match     []search.Segment // the matched text, highlighted
+	Member    string           // slug of the matched member, if any
+}
+
+// SearchHandler lists the artists matching ?q=
+func SearchHandler(w http.ResponseWriter, r *http.Request) {
+	// 1. Read the query; an empty one just shows the search form
+	data := SearchData{Query: r.URL.Query().Get("q")}
+
+	// 2. Search the catalog
+	cat, err := store.Current()
+	if err != nil {
+		log.Printf("Error loading catalog: %v", err)
+		RenderError(w, r, http.StatusServiceUnavailable, "Artist data is not available yet")
+		return
+	}
+	for _, result := range indexFor(cat).Search(data.Query, maxSearchResults) {
+		artist, _ := cat.Artist(result.ArtistID)
+		entry := SearchResult{
+			Artist:    artist,
+			MatchType: result.Field,
+			Match:     search.Segments(result.Text, result.Highlights),
+		}
+		if result.Field == search.FieldMember {
+			entry.Member = catalog.Slug(result.Text)
+		}
+		data.Results = append(data.Results, entry)
+	}
+
+	// 3. Render search.html template
+	renderTemplate(w, r, "search.html", data)
+}
+
+type SearchResponse struct {
+	Query   string            `json:"query"`
+	Results []SearchAPIResult `json:"results"`
+}
+
+// SearchAPIResult is a search hit with enough of the artist to list it
+type SearchAPIResult struct {
+	Artist catalog.ArtistRef `json:"artist"`
+	Image  string            `json:"image"`
+	search.Result
+}
+
+// SearchAPIHandler returns the artists matching ?q= as JSON, best first.
+// ?limit= caps the number of results.
+func SearchAPIHandler(w http.ResponseWriter, r *http.Request) {
+	// 1. Read the query and limit
+	query := r.URL.Query().Get("q")
+	limit := maxAPISearchResults
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		n, err := strconv.Atoi(limitStr)
+		if err != nil || n < 1 {
+			RenderError(w, r, http.StatusBadRequest, "Invalid limit parameter")
+			return
+		}
+		limit = min(n, maxAPISearchResults)
+	}
+
+	// 2. Search the catalog
+	cat, err := store.Current()
+	if err != nil {
+		log.Printf("Error loading catalog: %v", err)
+		RenderError(w, r, http.StatusServiceUnavailable, "Artist data is not available yet")
+		return
+	}
+
+	resp := SearchResponse{Query: query, Results: []SearchAPIResult{}}
+	for _, result := range indexFor(cat).Search(query, limit) {
+		artist, _ := cat.Artist(result.ArtistID)
+		resp.Results = append(resp.Results, SearchAPIResult{
+			Artist: catalog.ArtistRef{ID: artist.ID, Name: artist.Name},
+			Image:  artist.Image,
+			Result: result,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"groupie_tracker/api"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// contentSecurityPolicy only allows our own scripts and styles, and
+// images from the upstream API host that serves the artist pictures
+var contentSecurityPolicy = strings.Join([]string{
+	"default-src 'self'",
+	"img-src 'self' " + upstreamOrigin(),
+	"script-src 'self'",
+	"style-src 'self'",
+	"connect-src 'self'",
+	"object-src 'none'",
+	"base-uri 'self'",
+	"form-action 'self'",
+	"frame-ancestors 'none'",
+}, "; ")
+
+func upstreamOrigin() string {
+	u, err := url.Parse(api.BaseURL)
+	if err != nil {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// SecurityHeaders sets the headers every response should carry
+func SecurityHeaders(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h := w.Header()
+		h.Set("Content-Security-Policy", contentSecurityPolicy)
+		h.Set("X-Content-Type-Options", "nosniff")
+		h.Set("X-Frame-Options", "DENY")
+		h.Set("Referrer-Policy", "strict-origin-when-cross-origin")
+		h.Set("Permissions-Policy", "camera=(), microphone=(), geolocation=()")
+		if r.TLS != nil {
+			h.Set("Strict-Transport-Security", "max-age=63072000; includeSubDomains")
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSecurityHeaders(t *testing.T) {
+	setupCatalog(t)
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		target  string
+		code    int
+	}{
+		{"page", HomeHandler, "/", http.StatusOK},
+		{"error", NotFoundHandler, "/nowhere", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		rec := get(SecurityHeaders(tt.handler).ServeHTTP, tt.target, nil)
+		if rec.Code != tt.code {
+			t.Fatalf("%s: expected %d, got %d", tt.name, tt.code, rec.Code)
+		}
+		h := rec.Header()
+		if csp := h.Get("Content-Security-Policy"); !strings.Contains(csp, "default-src 'self'") || !strings.Contains(csp, "frame-ancestors 'none'") {
+			t.Errorf("%s: expected a restrictive CSP, got %q", tt.name, csp)
+		}
+		if got := h.Get("X-Content-Type-Options"); got != "nosniff" {
+			t.Errorf("%s: expected nosniff, got %q", tt.name, got)
+		}
+		if got := h.Get("X-Frame-Options"); got != "DENY" {
+			t.Errorf("%s: expected framing to be denied, got %q", tt.name, got)
+		}
+		if got := h.Get("Strict-Transport-Security"); got != "" {
+			t.Errorf("%s: expected no HSTS over plain HTTP, got %q", tt.name, got)
+		}
+	}
+}
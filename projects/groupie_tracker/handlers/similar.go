@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"groupie_tracker/catalog"
+	"groupie_tracker/models"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// maxSimilar is how many similar artists are recommended
+const maxSimilar = 5
+
+// reasonThreshold is the likeness from which a criterion is shown as a
+// reason for a recommendation
+const reasonThreshold = 0.5
+
+// similarityWeights ranks similar artists
+var similarityWeights = struct {
+	sync.Mutex
+	weights catalog.Weights
+}{weights: catalog.DefaultWeights}
+
+// similar is what recommendations caches: the neighbor lists of a
+// catalog and the weights they were ranked with
+type similar struct {
+	neighbors map[int][]catalog.Neighbor
+	weights   catalog.Weights
+}
+
+// recommendations caches the neighbor lists of the latest catalog
+var recommendations = newDerived("Similar artists", func(cat *catalog.Catalog) similar {
+	similarityWeights.Lock()
+	w := similarityWeights.weights
+	similarityWeights.Unlock()
+	return similar{cat.Similar(w, maxSimilar), w}
+})
+
+// SetSimilarityWeights changes how similar artists are ranked
+func SetSimilarityWeights(w catalog.Weights) {
+	similarityWeights.Lock()
+	similarityWeights.weights = w
+	similarityWeights.Unlock()
+	recommendations.Drop()
+}
+
+// similarFor returns the neighbor lists of cat and the weights used
+func similarFor(cat *catalog.Catalog) (map[int][]catalog.Neighbor, catalog.Weights) {
+	s := recommendations.Get(cat)
+	return s.neighbors, s.weights
+}
+
+// SimilarArtist is a recommendation ready for artist.html
+type SimilarArtist struct {
+	Artist  models.Artist
+	Reasons []string // i18n keys
+}
+
+// similarArtists resolves the neighbors of an artist for the artist page
+func similarArtists(cat *catalog.Catalog, artistID int) []SimilarArtist {
+	neighbors, weights := similarFor(cat)
+
+	var similar []SimilarArtist
+	for _, n := range neighbors[artistID] {
+		artist, _ := cat.Artist(n.Artist.ID)
+		similar = append(similar, SimilarArtist{Artist: artist, Reasons: reasons(n.Likeness, weights)})
+	}
+	return similar
+}
+
+// reasons explains a recommendation by the criteria it scores well on
+func reasons(l catalog.Likeness, w catalog.Weights) []string {
+	var out []string
+	if w.Cities > 0 && l.Cities >= reasonThreshold {
+		out = append(out, "Played in the same cities")
+	}
+	if w.Tours > 0 && l.Tours >= reasonThreshold {
+		out = append(out, "Toured at the same time")
+	}
+	if w.Era > 0 && l.Era >= reasonThreshold {
+		out = append(out, "Formed in the same era")
+	}
+	if w.Size > 0 && l.Size >= reasonThreshold {
+		out = append(out, "Similar line-up size")
+	}
+	return out
+}
+
+type SimilarResponse struct {
+	Artist  catalog.ArtistRef  `json:"artist"`
+	Weights catalog.Weights    `json:"weights"`
+	Similar []catalog.Neighbor `json:"similar"`
+}
+
+// SimilarAPIHandler serves /api/v1/artists/{id}/similar
+func SimilarAPIHandler(w http.ResponseWriter, r *http.Request) {
+	// 1. Validate the artist ID from the path
+	artistID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || artistID < 1 {
+		RenderError(w, r, http.StatusBadRequest, "Invalid artist ID")
+		return
+	}
+
+	cat, err := store.Current()
+	if err != nil {
+		log.Printf("Error loading catalog: %v", err)
+		RenderError(w, r, http.StatusServiceUnavailable, "Artist data is not available yet")
+		return
+	}
+
+	artist, found := cat.Artist(artistID)
+	if !found {
+		RenderError(w, r, http.StatusNotFound, "Artist not found")
+		return
+	}
+
+	// 2. Send the precomputed neighbors
+	neighbors, weights := similarFor(cat)
+	resp := SimilarResponse{
+		Artist:  catalog.ArtistRef{ID: artist.ID, Name: artist.Name},
+		Weights: weights,
+		Similar: neighbors[artistID],
+	}
+	if resp.Similar == nil {
+		resp.Similar = []catalog.Neighbor{}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
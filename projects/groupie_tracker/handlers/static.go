@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"groupie_tracker/assets"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// manifest fingerprints the static files for the "asset" template func
+var manifest *assets.Manifest
+
+// SetAssets wires the static file manifest used by StaticHandler and the templates
+func SetAssets(m *assets.Manifest) {
+	manifest = m
+}
+
+// StaticHandler serves the files of the manifest and nothing else: no
+// directory listings, no dotfiles. Fingerprinted URLs are cached for a
+// year, and precompressed .br/.gz siblings are sent to clients that
+// accept them. Mount it with http.StripPrefix.
+func StaticHandler(m *assets.Manifest) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			RenderError(w, r, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		// 1. Only files known to the manifest exist
+		name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+		file, immutable, ok := m.Lookup(name)
+		if !ok {
+			RenderError(w, r, http.StatusNotFound, "File not found")
+			return
+		}
+
+		// 2. Pick a precompressed variant if the client takes one
+		diskPath, encoding := m.Path(file), ""
+		for _, enc := range assets.Encodings() {
+			if variant, ok := file.Variants[enc]; ok && acceptsEncoding(r, enc) {
+				diskPath, encoding = variant, enc
+				break
+			}
+		}
+
+		f, err := os.Open(diskPath)
+		if err != nil {
+			RenderError(w, r, http.StatusNotFound, "File not found")
+			return
+		}
+		defer f.Close()
+
+		info, err := f.Stat()
+		if err != nil || info.IsDir() {
+			RenderError(w, r, http.StatusNotFound, "File not found")
+			return
+		}
+
+		// 3. Headers: the type of the original file, and caching
+		h := w.Header()
+		if ctype := mime.TypeByExtension(path.Ext(file.Name)); ctype != "" {
+			h.Set("Content-Type", ctype)
+		}
+		if len(file.Variants) > 0 {
+			h.Add("Vary", "Accept-Encoding")
+		}
+		if encoding != "" {
+			h.Set("Content-Encoding", encoding)
+		}
+		h.Set("ETag", strconv.Quote(file.Hash+encoding))
+		if immutable {
+			h.Set("Cache-Control", "public, max-age=31536000, immutable")
+		} else {
+			h.Set("Cache-Control", "no-cache")
+		}
+
+		http.ServeContent(w, r, file.Name, info.ModTime(), f)
+	})
+}
+
+// acceptsEncoding reports whether Accept-Encoding allows coding with q > 0
+func acceptsEncoding(r *http.Request, coding string) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(strings.TrimSpace(name), coding) {
+			continue
+		}
+		q, found := strings.CutPrefix(strings.TrimSpace(params), "q=")
+		if !found {
+			return true
+		}
+		value, err := strconv.ParseFloat(q, 64)
+		return err == nil && value > 0
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"groupie_tracker/assets"
+)
+
+// testAssets builds a static directory with a stylesheet, its
+// precompressed variants, a dotfile and a subdirectory
+func testAssets(t *testing.T) *assets.Manifest {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"css/style.css":    "body{}",
+		"css/style.css.br": "brotli",
+		"css/style.css.gz": "gzip",
+		"js/app.js":        "let x",
+		".env":             "SECRET=1",
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(p), 0o755)
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m, err := assets.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+// getStatic requests a file from StaticHandler mounted under /static/
+func getStatic(m *assets.Manifest, target string, headers map[string]string) *httptest.ResponseRecorder {
+	h := http.StripPrefix("/static/", StaticHandler(m))
+	return get(h.ServeHTTP, target, headers)
+}
+
+func TestStaticHandlerHidesUnlistedFiles(t *testing.T) {
+	setupCatalog(t)
+	m := testAssets(t)
+
+	for _, target := range []string{"/static/.env", "/static/css", "/static/css/", "/static/", "/static/css/style.css.gz", "/static/../main.go"} {
+		if rec := getStatic(m, target, nil); rec.Code != http.StatusNotFound {
+			t.Errorf("%s: expected 404, got %d", target, rec.Code)
+		}
+	}
+}
+
+func TestStaticHandlerCaching(t *testing.T) {
+	setupCatalog(t)
+	m := testAssets(t)
+
+	fingerprinted := m.URL("css/style.css")
+	rec := getStatic(m, fingerprinted, nil)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Header().Get("Cache-Control"), "immutable") {
+		t.Errorf("%s: expected an immutable file, got %d %q", fingerprinted, rec.Code, rec.Header().Get("Cache-Control"))
+	}
+	if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/css") {
+		t.Errorf("Expected the stylesheet's type, got %q", got)
+	}
+
+	rec = getStatic(m, "/static/css/style.css", nil)
+	if rec.Code != http.StatusOK || strings.Contains(rec.Header().Get("Cache-Control"), "immutable") {
+		t.Errorf("Expected a plain URL to be revalidated, got %d %q", rec.Code, rec.Header().Get("Cache-Control"))
+	}
+
+	rec = getStatic(m, "/static/css/style.css", map[string]string{"If-None-Match": rec.Header().Get("ETag")})
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("Expected 304 for a matching ETag, got %d", rec.Code)
+	}
+}
+
+func TestStaticHandlerPrecompressed(t *testing.T) {
+	setupCatalog(t)
+	m := testAssets(t)
+
+	tests := []struct {
+		acceptEncoding string
+		encoding       string
+		body           string
+	}{
+		{"", "", "body{}"},
+		{"gzip", "gzip", "gzip"},
+		{"gzip, br", "br", "brotli"},
+		{"br;q=0, gzip", "gzip", "gzip"},
+		{"identity", "", "body{}"},
+	}
+	for _, tt := range tests {
+		rec := getStatic(m, "/static/css/style.css", map[string]string{"Accept-Encoding": tt.acceptEncoding})
+		if got := rec.Header().Get("Content-Encoding"); got != tt.encoding || rec.Body.String() != tt.body {
+			t.Errorf("Accept-Encoding %q: expected %q encoding, got %q with %q", tt.acceptEncoding, tt.encoding, got, rec.Body.String())
+		}
+		if got := rec.Header().Get("Vary"); got != "Accept-Encoding" {
+			t.Errorf("Accept-Encoding %q: expected Vary: Accept-Encoding, got %q", tt.acceptEncoding, got)
+		}
+	}
+
+	// Files without variants do not vary
+	if got := getStatic(m, "/static/js/app.js", map[string]string{"Accept-Encoding": "gzip"}).Header().Get("Vary"); got != "" {
+		t.Errorf("Expected no Vary without variants, got %q", got)
+	}
+}
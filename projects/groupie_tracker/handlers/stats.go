@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"groupie_tracker/catalog"
+	"groupie_tracker/chart"
+	"groupie_tracker/export"
+	"groupie_tracker/i18n"
+	"html/template"
+	"log"
+	"net/http"
+)
+
+// catalogStats caches the aggregates of the latest catalog
+var catalogStats = newDerived("Statistics", (*catalog.Catalog).Stats)
+
+func statsFor(cat *catalog.Catalog) catalog.Stats {
+	return catalogStats.Get(cat)
+}
+
+type StatsData struct {
+	Stats   catalog.Stats
+	Charts  []StatsChart
+	Exports []string // tables that can be downloaded as CSV
+}
+
+// StatsChart is one chart of the stats page
+type StatsChart struct {
+	Title string
+	SVG   template.HTML
+}
+
+// StatsHandler shows aggregate figures and charts over the whole catalog
+func StatsHandler(w http.ResponseWriter, r *http.Request) {
+	// 1. Get the catalog
+	cat, err := store.Current()
+	if err != nil {
+		log.Printf("Error loading catalog: %v", err)
+		RenderError(w, r, http.StatusServiceUnavailable, "Artist data is not available yet")
+		return
+	}
+
+	locale := i18n.Negotiate(r)
+	if checkNotModified(w, r, cat.FetchedAt, "stats", locale.Tag) {
+		return
+	}
+
+	// 2. Draw the charts in the request's language
+	stats := statsFor(cat)
+	data := StatsData{Stats: stats, Exports: export.Tables}
+	add := func(title string, counts []catalog.Count, label func(string) string, horizontal bool) {
+		c := chart.BarChart{
+			Title:      locale.T(title),
+			Horizontal: horizontal,
+			Format:     func(n int) string { return locale.Number(n) },
+		}
+		for _, count := range counts {
+			c.Bars = append(c.Bars, chart.Bar{Label: label(count.Label), Value: count.Value})
+		}
+		data.Charts = append(data.Charts, StatsChart{Title: c.Title, SVG: c.SVG()})
+	}
+	same := func(label string) string { return label }
+	digits := func(label string) string { return locale.Number(label) }
+	decade := func(label string) string { return locale.T("%ss", locale.Number(label)) }
+
+	add("Artists by formation decade", stats.Decades, decade, false)
+	add("Concerts per year", stats.ConcertsPerYear, digits, false)
+	add("Top countries by concerts", stats.TopCountries, same, true)
+	add("Top cities by concerts", stats.TopCities, same, true)
+	add("Artists by number of members", stats.BandSizes, digits, false)
+
+	// 3. Render stats.html template
+	renderTemplate(w, r, "stats.html", data)
+}
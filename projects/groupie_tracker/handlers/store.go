@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"groupie_tracker/catalog"
+	"groupie_tracker/favorites"
+	"groupie_tracker/session"
+	"time"
+)
+
+// CatalogSource is what the handlers need from the catalog. The server
+// uses *catalog.Store; tests inject a fake.
+type CatalogSource interface {
+	// Current returns the latest catalog, or an error if none is available
+	Current() (*catalog.Catalog, error)
+	// ChangesSince returns the changelogs of refreshes after since
+	ChangesSince(since time.Time) []catalog.Changelog
+}
+
+// store is the catalog every handler renders from
+var store CatalogSource
+
+// sessions and favs back the "My artists" feature
+var (
+	sessions *session.Manager
+	favs     favorites.Store
+)
+
+// SetStore wires the catalog source used by the handlers
+func SetStore(s CatalogSource) {
+	store = s
+}
+
+// SetFavorites wires the session manager and favorites store used by the handlers
+func SetFavorites(m *session.Manager, f favorites.Store) {
+	sessions = m
+	favs = f
+}
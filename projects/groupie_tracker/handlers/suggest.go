@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"fmt"
+	"groupie_tracker/catalog"
+	"log"
+	"net/http"
+	"net/url"
+)
+
+// maxSuggestions is how many completions the search box shows
+const maxSuggestions = 8
+
+type SuggestResponse struct {
+	Query       string            `json:"query"`
+	Suggestions []SuggestionEntry `json:"suggestions"`
+}
+
+// SuggestionEntry is a catalog suggestion ready for the search box
+type SuggestionEntry struct {
+	Text  string `json:"text"`
+	Type  string `json:"type"`
+	Label string `json:"label"` // Type in the request's language
+	URL   string `json:"url"`
+}
+
+// SuggestHandler returns ranked completions for ?q= as JSON, for the
+// search-as-you-type box in script.js
+func SuggestHandler(w http.ResponseWriter, r *http.Request) {
+	// 1. Get the catalog
+	cat, err := store.Current()
+	if err != nil {
+		log.Printf("Error loading catalog: %v", err)
+		RenderError(w, r, http.StatusServiceUnavailable, "Artist data is not available yet")
+		return
+	}
+
+	// 2. Rank the suggestions and link each one to where it leads
+	locale := localeFor(w, r)
+	query := r.URL.Query().Get("q")
+
+	resp := SuggestResponse{Query: query, Suggestions: []SuggestionEntry{}}
+	for _, s := range cat.Suggest(query, maxSuggestions) {
+		entry := SuggestionEntry{Text: s.Text, Type: s.Type, Label: locale.T(s.Type)}
+		switch {
+		case s.Type == "member":
+			entry.URL = "/members/" + url.PathEscape(catalog.Slug(s.Text))
+		case s.ArtistID != 0:
+			entry.URL = fmt.Sprintf("/artist?id=%d", s.ArtistID)
+		default:
+			entry.URL = "/search?q=" + url.QueryEscape(s.Text)
+		}
+		resp.Suggestions = append(resp.Suggestions, entry)
+	}
+
+	// 3. Answers change with the catalog and the language only
+	w.Header().Set("Cache-Control", "private, max-age=60")
+	w.Header().Add("Vary", "Accept-Language")
+	writeJSON(w, http.StatusOK, resp)
+}
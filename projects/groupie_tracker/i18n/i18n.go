@@ -0,0 +1,120 @@
+// Package i18n holds the message catalogs and picks the locale of a request.
+//
+// Messages are keyed by their English text, gettext style, so English is
+// the source locale and needs no translations of its own.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// DefaultTag is used when nothing better matches the request
+const DefaultTag = "en"
+
+//go:embed locales/*.json
+var files embed.FS
+
+// Locale is one message catalog plus the formatting rules of its language
+type Locale struct {
+	Tag      string            `json:"-"`
+	Name     string            `json:"name"`
+	Dir      string            `json:"dir"`
+	Months   [12]string        `json:"months"`
+	Digits   string            `json:"digits"`
+	Messages map[string]string `json:"messages"`
+
+	digits *strings.Replacer
+}
+
+// locales is keyed by language tag; order is how they are offered to users
+var (
+	locales = map[string]*Locale{}
+	order   = []string{"en", "ar"}
+)
+
+func init() {
+	for _, tag := range order {
+		data, err := files.ReadFile("locales/" + tag + ".json")
+		if err != nil {
+			log.Fatalf("i18n: missing catalog for %s: %v", tag, err)
+		}
+
+		l := &Locale{Tag: tag}
+		if err := json.Unmarshal(data, l); err != nil {
+			log.Fatalf("i18n: invalid catalog for %s: %v", tag, err)
+		}
+
+		// Map ASCII digits to the locale's own digits, e.g. Arabic-Indic
+		if digits := []rune(l.Digits); len(digits) == 10 {
+			pairs := make([]string, 0, 20)
+			for i, d := range digits {
+				pairs = append(pairs, string(rune('0'+i)), string(d))
+			}
+			l.digits = strings.NewReplacer(pairs...)
+		}
+		locales[tag] = l
+	}
+}
+
+// Lookup returns the locale for a language tag such as "ar" or "ar-EG"
+func Lookup(tag string) (*Locale, bool) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if l, ok := locales[tag]; ok {
+		return l, true
+	}
+	// Fall back from a regional variant to its language
+	base, _, _ := strings.Cut(tag, "-")
+	l, ok := locales[base]
+	return l, ok
+}
+
+// Default returns the source locale
+func Default() *Locale {
+	return locales[DefaultTag]
+}
+
+// Supported returns every locale in the order they are offered to users
+func Supported() []*Locale {
+	list := make([]*Locale, len(order))
+	for i, tag := range order {
+		list[i] = locales[tag]
+	}
+	return list
+}
+
+// T translates a message, falling back to the English key.
+// Any args are applied to the translation with fmt.Sprintf.
+func (l *Locale) T(key string, args ...any) string {
+	msg, ok := l.Messages[key]
+	if !ok || msg == "" {
+		msg = key
+	}
+	if len(args) > 0 {
+		msg = fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
+
+// Number formats v with the locale's digits
+func (l *Locale) Number(v any) string {
+	s := fmt.Sprint(v)
+	if l.digits == nil {
+		return s
+	}
+	return l.digits.Replace(s)
+}
+
+// FormatDate formats a day as "16 April 2020" in the locale's language
+func (l *Locale) FormatDate(t time.Time) string {
+	return l.Number(t.Day()) + " " + l.Months[t.Month()-1] + " " + l.Number(t.Year())
+}
+
+// RTL reports whether the locale is written right to left
+func (l *Locale) RTL() bool {
+	return l.Dir == "rtl"
+}
@@ -0,0 +1,71 @@
+package i18n
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	got := ParseAcceptLanguage("en-US;q=0.5, ar-EG, fr;q=0, de;q=0.5")
+	want := []string{"ar-EG", "en-US", "de"}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name   string
+		url    string
+		cookie string
+		accept string
+		want   string
+	}{
+		{"default", "/", "", "", "en"},
+		{"accept-language region", "/", "", "ar-EG,en;q=0.8", "ar"},
+		{"cookie beats header", "/", "en", "ar", "en"},
+		{"query beats cookie", "/?lang=ar", "en", "", "ar"},
+		{"unknown query ignored", "/?lang=xx", "", "ar", "ar"},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, tt.url, nil)
+		if tt.cookie != "" {
+			r.AddCookie(&http.Cookie{Name: CookieName, Value: tt.cookie})
+		}
+		if tt.accept != "" {
+			r.Header.Set("Accept-Language", tt.accept)
+		}
+
+		if got := Negotiate(r).Tag; got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestFormatDate(t *testing.T) {
+	date := time.Date(2020, time.April, 16, 0, 0, 0, 0, time.UTC)
+	ar, _ := Lookup("ar")
+
+	if got := Default().FormatDate(date); got != "16 April 2020" {
+		t.Errorf("Expected English date, got %q", got)
+	}
+	if got := ar.FormatDate(date); got != "١٦ أبريل ٢٠٢٠" {
+		t.Errorf("Expected Arabic date, got %q", got)
+	}
+}
+
+func TestTranslateFallsBackToKey(t *testing.T) {
+	ar, _ := Lookup("ar")
+
+	if got := ar.T("Artist not found"); got != "الفنان غير موجود" {
+		t.Errorf("Expected Arabic translation, got %q", got)
+	}
+	if got := ar.T("Untranslated %d", 3); got != "Untranslated 3" {
+		t.Errorf("Expected fallback to the formatted key, got %q", got)
+	}
+}
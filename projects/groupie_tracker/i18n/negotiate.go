@@ -0,0 +1,83 @@
+package i18n
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CookieName remembers the language picked with ?lang=
+const CookieName = "lang"
+
+// Negotiate picks the locale of a request: an explicit ?lang= first,
+// then the lang cookie, then Accept-Language, then the default
+func Negotiate(r *http.Request) *Locale {
+	if l, ok := Lookup(r.URL.Query().Get("lang")); ok {
+		return l
+	}
+	if cookie, err := r.Cookie(CookieName); err == nil {
+		if l, ok := Lookup(cookie.Value); ok {
+			return l
+		}
+	}
+	for _, tag := range ParseAcceptLanguage(r.Header.Get("Accept-Language")) {
+		if l, ok := Lookup(tag); ok {
+			return l
+		}
+	}
+	return Default()
+}
+
+// Remember stores an explicit ?lang= choice in a cookie so it sticks
+// across pages
+func Remember(w http.ResponseWriter, r *http.Request, l *Locale) {
+	if r.URL.Query().Get("lang") == "" {
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     CookieName,
+		Value:    l.Tag,
+		Path:     "/",
+		MaxAge:   365 * 24 * 60 * 60,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// ParseAcceptLanguage returns the language tags of an Accept-Language
+// header, most preferred first. Tags with q=0 are dropped.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q > 0 {
+			tags = append(tags, weighted{tag, q})
+		}
+	}
+
+	// Stable so equal weights keep the client's order
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	result := make([]string, len(tags))
+	for i, t := range tags {
+		result[i] = t.tag
+	}
+	return result
+}
@@ -0,0 +1,24 @@
+package ics
+
+import (
+	"fmt"
+	"groupie_tracker/catalog"
+	"groupie_tracker/models"
+)
+
+// ConcertEvents turns an artist's concerts into events. The UID only
+// depends on the artist, location and date, so subscribed calendars
+// update events in place instead of duplicating them.
+func ConcertEvents(artist models.Artist, concerts []catalog.Concert) []Event {
+	events := make([]Event, 0, len(concerts))
+	for _, c := range concerts {
+		location := catalog.FormatLocation(c.Location)
+		events = append(events, Event{
+			UID:      fmt.Sprintf("concert-%d-%s-%s@groupie-tracker", artist.ID, c.Location, c.Date.Format("20060102")),
+			Date:     c.Date,
+			Summary:  fmt.Sprintf("%s live in %s", artist.Name, location),
+			Location: location,
+		})
+	}
+	return events
+}
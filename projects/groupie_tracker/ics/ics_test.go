@@ -0,0 +1,64 @@
+package ics
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"groupie_tracker/catalog"
+	"groupie_tracker/models"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+func TestWriteGolden(t *testing.T) {
+	cat := catalog.New(
+		[]models.Artist{{ID: 1, Name: "Queen"}},
+		nil,
+		nil,
+		[]models.Relation{{ID: 1, DatesLocations: map[string][]string{
+			"north_carolina-usa":  {"16-04-2020"},
+			"dunedin-new_zealand": {"10-02-2020"},
+			"saitama-japan":       {"26-01-2020"},
+		}}},
+	)
+	artist, _ := cat.Artist(1)
+
+	var buf bytes.Buffer
+	err := Write(&buf, Calendar{
+		Name:   "Queen - Concerts; live, on tour",
+		Events: ConcertEvents(artist, cat.Concerts(1)),
+		Stamp:  time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("Expected no error writing calendar, got: %v", err)
+	}
+
+	golden := filepath.Join("testdata", "queen.ics")
+	if *update {
+		os.WriteFile(golden, buf.Bytes(), 0o644)
+	}
+
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("Could not read golden file: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("Calendar does not match %s (run with -update to refresh)\ngot:\n%s", golden, buf.String())
+	}
+}
+
+func TestWriteFoldsLongLines(t *testing.T) {
+	var buf bytes.Buffer
+	Write(&buf, Calendar{Name: strings.Repeat("é", 100)})
+
+	for _, line := range strings.Split(buf.String(), "\r\n") {
+		if len(line) > maxLineOctets {
+			t.Errorf("Line exceeds %d octets: %q", maxLineOctets, line)
+		}
+	}
+}
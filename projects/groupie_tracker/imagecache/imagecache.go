@@ -0,0 +1,137 @@
+// Package imagecache keeps copies of the artist pictures in memory, so
+// pages do not wait on the upstream host for every image.
+package imagecache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxImageBytes bounds a single picture; anything larger is refused
+const maxImageBytes = 5 << 20
+
+// ErrTooLarge is returned for a picture over maxImageBytes
+var ErrTooLarge = errors.New("image too large")
+
+// Image is a cached picture
+type Image struct {
+	Data        []byte
+	ContentType string
+	ETag        string
+	FetchedAt   time.Time
+}
+
+// Cache fetches pictures on first use and keeps them until it holds more
+// than its byte budget, when the oldest are dropped. It is safe for
+// concurrent use.
+type Cache struct {
+	client   *http.Client
+	maxBytes int64
+
+	mu     sync.Mutex
+	images map[string]Image
+	order  []string // URLs, oldest first
+	size   int64
+}
+
+// New creates an empty cache holding up to maxBytes of pictures, fetched
+// with client
+func New(client *http.Client, maxBytes int64) *Cache {
+	return &Cache{client: client, maxBytes: maxBytes, images: make(map[string]Image)}
+}
+
+// Get returns the picture at url, fetching it if it is not cached
+func (c *Cache) Get(url string) (Image, error) {
+	c.mu.Lock()
+	img, ok := c.images[url]
+	c.mu.Unlock()
+	if ok {
+		return img, nil
+	}
+
+	// Fetch without the lock; two visitors may fetch the same picture,
+	// which is cheaper than making everyone wait on one slow image
+	img, err := c.fetch(url)
+	if err != nil {
+		return Image{}, err
+	}
+	c.put(url, img)
+	return img, nil
+}
+
+func (c *Cache) fetch(url string) (Image, error) {
+	resp, err := c.client.Get(url)
+	if err != nil {
+		return Image{}, fmt.Errorf("failed to fetch image %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Image{}, fmt.Errorf("image %s returned bad status: %d", url, resp.StatusCode)
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if !strings.HasPrefix(contentType, "image/") {
+		return Image{}, fmt.Errorf("image %s has content type %q", url, contentType)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageBytes+1))
+	if err != nil {
+		return Image{}, fmt.Errorf("failed to read image %s: %w", url, err)
+	}
+	if len(data) > maxImageBytes {
+		return Image{}, fmt.Errorf("image %s: %w", url, ErrTooLarge)
+	}
+
+	sum := sha256.Sum256(data)
+	return Image{
+		Data:        data,
+		ContentType: contentType,
+		ETag:        `"` + hex.EncodeToString(sum[:8]) + `"`,
+		FetchedAt:   time.Now(),
+	}, nil
+}
+
+// put stores img, dropping the oldest pictures past the byte budget
+func (c *Cache) put(url string, img Image) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.images[url]; ok {
+		return // fetched by someone else meanwhile
+	}
+	c.images[url] = img
+	c.order = append(c.order, url)
+	c.size += int64(len(img.Data))
+
+	for c.size > c.maxBytes && len(c.order) > 0 {
+		oldest := c.order[0]
+		c.order = slices.Delete(c.order, 0, 1)
+		c.size -= int64(len(c.images[oldest].Data))
+		delete(c.images, oldest)
+	}
+}
+
+// Drop forgets every picture, so each is fetched again on next use
+func (c *Cache) Drop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.images = make(map[string]Image)
+	c.order = nil
+	c.size = 0
+}
+
+// Stats tells how many pictures are cached and their total size
+func (c *Cache) Stats() (images int, bytes int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.images), c.size
+}
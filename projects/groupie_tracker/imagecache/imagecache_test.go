@@ -0,0 +1,104 @@
+package imagecache
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// upstream serves 100-byte pictures under /img/, a page under /page and
+// an oversized picture under /huge, counting the requests
+func upstream(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/img/"):
+			w.Header().Set("Content-Type", "image/jpeg")
+			w.Write(bytes.Repeat([]byte(r.URL.Path[len("/img/"):]), 100))
+		case r.URL.Path == "/huge":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write(make([]byte, maxImageBytes+1))
+		case r.URL.Path == "/page":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func TestGetCaches(t *testing.T) {
+	srv, requests := upstream(t)
+	c := New(srv.Client(), 1<<20)
+
+	first, err := c.Get(srv.URL + "/img/a")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(first.Data) != 100 || first.ContentType != "image/jpeg" || first.ETag == "" {
+		t.Errorf("Unexpected image %d bytes %q %q", len(first.Data), first.ContentType, first.ETag)
+	}
+	if second, _ := c.Get(srv.URL + "/img/a"); second.ETag != first.ETag || requests.Load() != 1 {
+		t.Errorf("Expected the second read to come from the cache, got %d requests", requests.Load())
+	}
+	if images, size := c.Stats(); images != 1 || size != 100 {
+		t.Errorf("Expected 1 image of 100 bytes, got %d of %d", images, size)
+	}
+
+	c.Drop()
+	if images, _ := c.Stats(); images != 0 {
+		t.Errorf("Expected no image after Drop, got %d", images)
+	}
+	c.Get(srv.URL + "/img/a")
+	if requests.Load() != 2 {
+		t.Errorf("Expected a dropped image to be fetched again, got %d requests", requests.Load())
+	}
+}
+
+func TestGetEvictsOldest(t *testing.T) {
+	srv, requests := upstream(t)
+	c := New(srv.Client(), 250)
+
+	for _, name := range []string{"a", "b", "c"} {
+		if _, err := c.Get(srv.URL + "/img/" + name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if images, size := c.Stats(); images != 2 || size != 200 {
+		t.Errorf("Expected 2 images of 200 bytes within the budget, got %d of %d", images, size)
+	}
+
+	c.Get(srv.URL + "/img/c")
+	if requests.Load() != 3 {
+		t.Errorf("Expected the newest image to be kept, got %d requests", requests.Load())
+	}
+	c.Get(srv.URL + "/img/a")
+	if requests.Load() != 4 {
+		t.Errorf("Expected the oldest image to be evicted, got %d requests", requests.Load())
+	}
+}
+
+func TestGetRefusesNonImages(t *testing.T) {
+	srv, _ := upstream(t)
+	c := New(srv.Client(), 1<<20)
+
+	for _, path := range []string{"/page", "/missing"} {
+		if _, err := c.Get(srv.URL + path); err == nil {
+			t.Errorf("%s: expected an error", path)
+		}
+	}
+	if _, err := c.Get(srv.URL + "/huge"); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Expected ErrTooLarge, got %v", err)
+	}
+	if images, _ := c.Stats(); images != 0 {
+		t.Errorf("Expected failures not to be cached, got %d images", images)
+	}
+}
@@ -1,25 +1,204 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"groupie_tracker/assets"
+	"groupie_tracker/catalog"
+	"groupie_tracker/events"
+	"groupie_tracker/favorites"
 	"groupie_tracker/handlers"
+	"groupie_tracker/imagecache"
+	"groupie_tracker/ratelimit"
+	"groupie_tracker/session"
 	"log"
+	"math"
 	"net/http"
+	"os"
+	"path/filepath"
+	"time"
 )
 
 func main() {
-	// Serve static files (CSS, JS)
-	fs := http.FileServer(http.Dir("./static"))
-	http.Handle("/static/", http.StripPrefix("/static/", fs))
+	// Subcommands
+	if len(os.Args) > 1 && os.Args[1] == "check" {
+		os.Exit(runCheck(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
+	dataDir := flag.String("data", "", "directory of JSON files to serve instead of the upstream API")
+	snapshot := flag.String("snapshot", "catalog.snapshot.json", "catalog snapshot file (empty to disable)")
+	snapshotDir := flag.String("snapshot-dir", "", "directory of snapshots the admin page can load (default: the -snapshot file's directory)")
+	refresh := flag.Duration("refresh", 30*time.Minute, "interval between catalog refreshes")
+	favoritesFile := flag.String("favorites", "favorites.json", "favorites file (empty to keep favorites in memory)")
+	rateHTML := flag.Float64("rate-html", 5, "page requests per second allowed per client")
+	rateAPI := flag.Float64("rate-api", 10, "JSON API requests per second allowed per client")
+	rateSearch := flag.Float64("rate-search", 2, "search requests per second allowed per client")
+	rateSuggest := flag.Float64("rate-suggest", 10, "search suggestion requests per second allowed per client")
+	similarWeights := flag.String("similar-weights", "", "weights of similar artist criteria, e.g. cities=0.4,tours=0.3,era=0.2,size=0.1")
+	imageCacheMB := flag.Int64("image-cache", 64, "megabytes of artist pictures to keep in memory (0 to let browsers load them from upstream)")
+	trustProxy := flag.Bool("trust-proxy", false, "identify clients by X-Forwarded-For (only behind a reverse proxy)")
+	flag.Parse()
+
+	// A rate limiter needs a positive rate to ever let a request through
+	for _, rate := range []struct {
+		flag  string
+		value float64
+	}{{"rate-html", *rateHTML}, {"rate-api", *rateAPI}, {"rate-search", *rateSearch}, {"rate-suggest", *rateSuggest}} {
+		if !(rate.value > 0) || math.IsInf(rate.value, 1) {
+			log.Fatalf("Error in -%s: expected a positive number of requests per second, got %v", rate.flag, rate.value)
+		}
+	}
+
+	// Catalog data comes from the upstream API unless a directory is given
+	var source catalog.DataSource = catalog.HTTPSource{}
+	if *dataDir != "" {
+		source = catalog.DirSource{Dir: *dataDir}
+	}
+
+	// Similar artists are ranked with these weights
+	if *similarWeights != "" {
+		weights, err := catalog.ParseWeights(*similarWeights)
+		if err != nil {
+			log.Fatalf("Error in -similar-weights: %v", err)
+		}
+		handlers.SetSimilarityWeights(weights)
+	}
+
+	store := catalog.NewStore(source, *snapshot)
+	// Search, statistics, similar artists and nearby concerts are
+	// computed once per refresh instead of on the first request
+	store.OnRefresh(handlers.PrecomputeDerived)
+
+	// Load the last snapshot first so we have something to show even if
+	// the upstream API is down, then try to get fresh data
+	if *snapshot != "" {
+		if err := store.LoadSnapshot(); err != nil {
+			log.Printf("No usable snapshot: %v", err)
+		}
+	}
+	if err := store.Refresh(); err != nil {
+		log.Printf("Error fetching catalog: %v", err)
+	}
+	// Tell open pages when the background refresh finds new data
+	hub := events.NewHub(16)
+	hub.ExportMetrics()
+	handlers.SetEvents(hub)
+	store.OnRefresh(handlers.PublishRefresh)
+
+	go store.Run(*refresh, nil)
+	store.ExportMetrics()
+	handlers.SetStore(store)
+
+	// The admin pages are only served with GROUPIE_ADMIN_TOKEN set
+	adminToken := os.Getenv("GROUPIE_ADMIN_TOKEN")
+	if adminToken == "" {
+		log.Println("GROUPIE_ADMIN_TOKEN not set, /admin is disabled")
+	}
+	if *snapshotDir == "" && *snapshot != "" {
+		*snapshotDir = filepath.Dir(*snapshot)
+	}
+	handlers.SetAdmin(store, adminToken, *snapshotDir)
+
+	// Sessions are signed with GROUPIE_SESSION_SECRET so they survive restarts
+	secret := []byte(os.Getenv("GROUPIE_SESSION_SECRET"))
+	if len(secret) == 0 {
+		log.Println("GROUPIE_SESSION_SECRET not set, sessions will not survive a restart")
+		secret = session.RandomSecret()
+	}
+
+	var favs favorites.Store = favorites.NewMemoryStore()
+	if *favoritesFile != "" {
+		fileStore, err := favorites.NewFileStore(*favoritesFile)
+		if err != nil {
+			log.Fatalf("Error opening favorites: %v", err)
+		}
+		favs = fileStore
+	}
+	handlers.SetFavorites(session.NewManager(secret), favs)
+
+	// Serve static files (CSS, JS) under content-hashed URLs
+	manifest, err := assets.Load("./static")
+	if err != nil {
+		log.Fatalf("Error loading static files: %v", err)
+	}
+	handlers.SetAssets(manifest)
+	http.Handle("/static/", http.StripPrefix("/static/", handlers.StaticHandler(manifest)))
+
+	// Artist pictures are kept in memory so pages do not wait on upstream
+	if *imageCacheMB > 0 {
+		handlers.SetImages(imagecache.New(&http.Client{Timeout: 10 * time.Second}, *imageCacheMB<<20))
+	}
 
 	// Register handlers
 	http.HandleFunc("/", handlers.HomeHandler)
 	http.HandleFunc("/artist", handlers.ArtistHandler)
 
+	// Personal watchlist
+	http.HandleFunc("/favorites", handlers.FavoritesHandler)
+	http.HandleFunc("/favorites/add", handlers.FavoriteAddHandler)
+	http.HandleFunc("/favorites/remove", handlers.FavoriteRemoveHandler)
+
+	// Band members across line-ups
+	http.HandleFunc("/members/{slug}", handlers.MemberHandler)
+
+	// Catalog statistics
+	http.HandleFunc("/stats", handlers.StatsHandler)
+
+	// Spreadsheet export
+	http.HandleFunc("/export/{file}", handlers.ExportHandler)
+
+	// Similar artists
+	http.HandleFunc("/api/v1/artists/{id}/similar", handlers.SimilarAPIHandler)
+
+	// Concerts near a point
+	http.HandleFunc("/api/v1/concerts/near", handlers.NearConcertsHandler)
+
+	// Side by side comparison
+	http.HandleFunc("/compare", handlers.CompareHandler)
+	http.HandleFunc("/api/v1/compare", handlers.CompareAPIHandler)
+
+	// Calendar feeds
+	http.HandleFunc("/artists/{id}/concerts.ics", handlers.ArtistCalendarHandler)
+	http.HandleFunc("/artists/{id}/image", handlers.ArtistImageHandler)
+	http.HandleFunc("/favorites.ics", handlers.FavoritesCalendarHandler)
+
+	// Catalog change tracking
+	http.HandleFunc("/api/v1/changes", handlers.ChangesHandler)
+	http.HandleFunc("/changes.atom", handlers.ChangesFeedHandler)
+
+	// Refresh notifications
+	http.HandleFunc("/events", handlers.EventsHandler)
+
+	// Operator dashboard
+	http.HandleFunc("/admin", handlers.AdminHandler)
+	http.HandleFunc("/admin/{action}", handlers.AdminActionHandler)
+
+	// Prometheus metrics
+	http.HandleFunc("/metrics", handlers.MetricsHandler)
+
 	// Search/filter feature (client-server interaction requirement)
-	// http.HandleFunc("/search", handlers.SearchHandler)
+	http.HandleFunc("/search", handlers.SearchHandler)
+	http.HandleFunc("/search/suggest", handlers.SuggestHandler)
+	http.HandleFunc("/api/v1/search", handlers.SearchAPIHandler)
+
+	// Each budget allows short bursts of a few seconds worth of requests
+	limits := handlers.RateLimits{
+		HTML:       ratelimit.New(*rateHTML, int(*rateHTML*4)+1),
+		API:        ratelimit.New(*rateAPI, int(*rateAPI*4)+1),
+		Search:     ratelimit.New(*rateSearch, int(*rateSearch*4)+1),
+		Suggest:    ratelimit.New(*rateSuggest, int(*rateSuggest*4)+1),
+		TrustProxy: *trustProxy,
+	}
+
+	// Middleware, innermost first
+	var handler http.Handler = http.DefaultServeMux
+	handler = handlers.RateLimit(limits, handler)
+	handler = handlers.Instrument(http.DefaultServeMux, handler)
+	handler = handlers.Compress(handler)
+	handler = handlers.SecurityHeaders(handler)
+	handler = handlers.Recover(handler)
 
 	// Start server
 	fmt.Println("Server running on http://localhost:8080")
-	log.Fatal(http.ListenAndServe(":8080", nil))
+	log.Fatal(http.ListenAndServe(":8080", handler))
 }
@@ -0,0 +1,143 @@
+// Package metrics is a small hand-rolled Prometheus client: metrics are
+// registered once and written out in the Prometheus text format.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// collector is anything the registry can write out
+type collector interface {
+	name() string
+	writeTo(w io.Writer)
+}
+
+// Registry holds the metrics exposed together
+type Registry struct {
+	mu         sync.Mutex
+	collectors []collector
+}
+
+// Default is the registry the package level constructors register with
+var Default = &Registry{}
+
+func (reg *Registry) register(c collector) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	for _, existing := range reg.collectors {
+		if existing.name() == c.name() {
+			panic("metrics: duplicate metric " + c.name())
+		}
+	}
+	reg.collectors = append(reg.collectors, c)
+}
+
+// WriteText writes every metric in the Prometheus text exposition format
+func (reg *Registry) WriteText(w io.Writer) {
+	reg.mu.Lock()
+	collectors := append([]collector(nil), reg.collectors...)
+	reg.mu.Unlock()
+
+	sort.Slice(collectors, func(i, j int) bool { return collectors[i].name() < collectors[j].name() })
+	for _, c := range collectors {
+		c.writeTo(w)
+	}
+}
+
+// vec holds one value per combination of label values
+type vec[T any] struct {
+	metricName string
+	help       string
+	labels     []string
+
+	mu     sync.Mutex
+	series map[string]*T
+	keys   map[string][]string
+	newT   func() *T
+}
+
+func newVec[T any](name, help string, labels []string, newT func() *T) *vec[T] {
+	return &vec[T]{
+		metricName: name,
+		help:       help,
+		labels:     labels,
+		series:     make(map[string]*T),
+		keys:       make(map[string][]string),
+		newT:       newT,
+	}
+}
+
+func (v *vec[T]) name() string { return v.metricName }
+
+// with returns the series for the label values, creating it on first use
+func (v *vec[T]) with(values []string) *T {
+	if len(values) != len(v.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", v.metricName, len(v.labels), len(values)))
+	}
+	key := strings.Join(values, "\xff")
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	s, ok := v.series[key]
+	if !ok {
+		s = v.newT()
+		v.series[key] = s
+		v.keys[key] = append([]string(nil), values...)
+	}
+	return s
+}
+
+// each calls fn for every series, ordered by label values
+func (v *vec[T]) each(fn func(labels string, s *T)) {
+	v.mu.Lock()
+	keys := make([]string, 0, len(v.series))
+	for k := range v.series {
+		keys = append(keys, k)
+	}
+	v.mu.Unlock()
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		v.mu.Lock()
+		s, values := v.series[k], v.keys[k]
+		v.mu.Unlock()
+		fn(formatLabels(v.labels, values), s)
+	}
+}
+
+func (v *vec[T]) writeHeader(w io.Writer, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", v.metricName, v.help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", v.metricName, kind)
+}
+
+// formatLabels renders {a="x",b="y"}, or nothing without labels
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	pairs := make([]string, len(names))
+	for i := range names {
+		pairs[i] = names[i] + "=" + strconv.Quote(values[i])
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// withLabel adds one more label to already formatted labels
+func withLabel(labels, name, value string) string {
+	pair := name + "=" + strconv.Quote(value)
+	if labels == "" {
+		return "{" + pair + "}"
+	}
+	return labels[:len(labels)-1] + "," + pair + "}"
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteText(t *testing.T) {
+	reg := &Registry{}
+	saved := Default
+	Default = reg
+	defer func() { Default = saved }()
+
+	requests := NewCounterVec("test_requests_total", "Requests.", "route")
+	requests.With("/b").Inc()
+	requests.With("/a").Inc()
+	requests.With("/a").Inc()
+
+	latency := NewHistogramVec("test_latency_seconds", "Latency.", []float64{0.1, 1}, "route")
+	latency.With("/a").Observe(0.05)
+	latency.With("/a").Observe(0.5)
+
+	NewGaugeFunc("test_up", "Up.", func() float64 { return 1 })
+
+	var buf bytes.Buffer
+	reg.WriteText(&buf)
+
+	want := `# HELP test_latency_seconds Latency.
+# TYPE test_latency_seconds histogram
+test_latency_seconds_bucket{route="/a",le="0.1"} 1
+test_latency_seconds_bucket{route="/a",le="1"} 2
+test_latency_seconds_bucket{route="/a",le="+Inf"} 2
+test_latency_seconds_sum{route="/a"} 0.55
+test_latency_seconds_count{route="/a"} 2
+# HELP test_requests_total Requests.
+# TYPE test_requests_total counter
+test_requests_total{route="/a"} 2
+test_requests_total{route="/b"} 1
+# HELP test_up Up.
+# TYPE test_up gauge
+test_up 1
+`
+	if got := buf.String(); got != want {
+		t.Errorf("Unexpected exposition:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestDuplicateMetricPanics(t *testing.T) {
+	reg := &Registry{}
+	saved := Default
+	Default = reg
+	defer func() { Default = saved }()
+
+	NewCounterVec("dup_total", "Dup.")
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected registering a metric twice to panic")
+		}
+	}()
+	NewCounterVec("dup_total", "Dup.")
+}
@@ -0,0 +1,94 @@
+// Package ratelimit implements per-client token buckets.
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepEvery is how often idle buckets are dropped
+const sweepEvery = time.Minute
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Limiter hands out rate tokens per key (usually a client IP).
+// Each key may burst up to burst requests, refilled at rate per second.
+// It is safe for concurrent use.
+type Limiter struct {
+	rate  float64
+	burst float64
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+
+	// now is replaceable in tests
+	now func() time.Time
+}
+
+// New creates a limiter allowing rate requests per second with the given
+// burst. It panics unless rate is a positive finite number and burst is
+// at least 1, since such a limiter could never let a request through.
+func New(rate float64, burst int) *Limiter {
+	if !(rate > 0) || math.IsInf(rate, 1) {
+		panic(fmt.Sprintf("ratelimit: rate must be positive and finite, got %v", rate))
+	}
+	if burst < 1 {
+		panic(fmt.Sprintf("ratelimit: burst must be at least 1, got %d", burst))
+	}
+	return &Limiter{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Allow takes a token for key. When none is left it reports how long
+// the client should wait before the next request can succeed.
+func (l *Limiter) Allow(key string) (ok bool, retryAfter time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, found := l.buckets[key]
+	if !found {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+
+	// Refill for the time elapsed since the last request
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	wait := (1 - b.tokens) / l.rate
+	return false, time.Duration(wait * float64(time.Second))
+}
+
+// sweep drops buckets that have been idle long enough to be full again,
+// since a fresh bucket behaves the same. The caller must hold l.mu.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepEvery {
+		return
+	}
+	l.lastSweep = now
+
+	refill := time.Duration(l.burst / l.rate * float64(time.Second))
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= refill {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package ratelimit
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestAllowBurstThenRefill(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := New(2, 3)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if ok, _ := l.Allow("1.2.3.4"); !ok {
+			t.Fatalf("Expected request %d of the burst to be allowed", i+1)
+		}
+	}
+
+	ok, retry := l.Allow("1.2.3.4")
+	if ok {
+		t.Fatal("Expected the request after the burst to be rejected")
+	}
+	if retry != 500*time.Millisecond {
+		t.Errorf("Expected to retry after 500ms, got %v", retry)
+	}
+
+	if ok, _ := l.Allow("5.6.7.8"); !ok {
+		t.Error("Expected another client to have its own bucket")
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if ok, _ := l.Allow("1.2.3.4"); !ok {
+		t.Error("Expected a token to be refilled after 500ms")
+	}
+}
+
+func TestNewRejectsUnusableLimits(t *testing.T) {
+	tests := []struct {
+		rate  float64
+		burst int
+	}{
+		{0, 1},
+		{-1, 1},
+		{math.NaN(), 1},
+		{math.Inf(1), 1},
+		{1, 0},
+	}
+	for _, tt := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("New(%v, %d): expected a panic", tt.rate, tt.burst)
+				}
+			}()
+			New(tt.rate, tt.burst)
+		}()
+	}
+}
@@ -0,0 +1,46 @@
+package search
+
+import "sort"
+
+// Segment is a piece of highlighted text
+type Segment struct {
+	Text  string
+	Match bool
+}
+
+// Segments cuts text into matched and unmatched pieces, for templates
+// that cannot slice strings themselves
+func Segments(text string, spans []Span) []Segment {
+	var segments []Segment
+	last := 0
+	for _, span := range mergeSpans(spans) {
+		if span.Start < last || span.End > len(text) {
+			continue
+		}
+		if span.Start > last {
+			segments = append(segments, Segment{Text: text[last:span.Start]})
+		}
+		segments = append(segments, Segment{Text: text[span.Start:span.End], Match: true})
+		last = span.End
+	}
+	if last < len(text) {
+		segments = append(segments, Segment{Text: text[last:]})
+	}
+	return segments
+}
+
+// mergeSpans sorts spans and joins those that overlap or touch
+func mergeSpans(spans []Span) []Span {
+	sorted := append([]Span(nil), spans...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+
+	merged := sorted[:0]
+	for _, span := range sorted {
+		if n := len(merged); n > 0 && span.Start <= merged[n-1].End {
+			merged[n-1].End = max(merged[n-1].End, span.End)
+			continue
+		}
+		merged = append(merged, span)
+	}
+	return merged
+}
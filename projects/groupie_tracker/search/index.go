@@ -0,0 +1,297 @@
+// Package search is a small in-memory full text index over the catalog.
+// It tolerates typos ("Pink Floid", "los angelos"), ranks results by
+// relevance and reports which parts of the text matched.
+package search
+
+import (
+	"groupie_tracker/catalog"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Fields an artist can match on. They are also i18n catalog keys.
+const (
+	FieldArtist       = "artist"
+	FieldMember       = "member"
+	FieldLocation     = "location"
+	FieldFirstAlbum   = "first album"
+	FieldCreationDate = "creation date"
+)
+
+// fieldWeights rank a match in the artist's name above the same match
+// in one of its members or locations
+var fieldWeights = map[string]float64{
+	FieldArtist:       1.0,
+	FieldMember:       0.9,
+	FieldLocation:     0.8,
+	FieldFirstAlbum:   0.7,
+	FieldCreationDate: 0.7,
+}
+
+// Scores of a single query word against an indexed word
+const (
+	scoreExact      = 1.0
+	scorePrefix     = 0.6 // plus up to 0.3 for how much of the word was typed
+	scoreFuzzy      = 0.7 // for one edit, less for each further edit
+	scorePerEdit    = 0.15
+	bonusWholeField = 0.25 // every word of the field matched exactly
+	bonusFirstWord  = 0.05 // the query starts where the field starts
+)
+
+// Span is a matched byte range [Start, End) of a Result's Text
+type Span struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// Result is an artist matching a query
+type Result struct {
+	ArtistID   int     `json:"artistId"`
+	Score      float64 `json:"score"`
+	Field      string  `json:"field"` // the field that matched best
+	Text       string  `json:"text"`  // its text
+	Highlights []Span  `json:"highlights"`
+}
+
+type document struct {
+	artistID int
+	field    string
+	text     string
+	tokens   []token
+}
+
+// posting is an occurrence of a term: token pos of document doc
+type posting struct {
+	doc, pos int
+}
+
+// Index is an immutable search index. Build it once per catalog.
+type Index struct {
+	docs     []document
+	postings map[string][]posting // term -> occurrences
+	terms    []string             // sorted, for prefix lookups
+	grams    map[string][]string  // trigram -> terms containing it
+}
+
+// Build indexes the artist names, members, locations, first albums and
+// creation dates of a catalog
+func Build(cat *catalog.Catalog) *Index {
+	ix := &Index{
+		postings: make(map[string][]posting),
+		grams:    make(map[string][]string),
+	}
+
+	for _, artist := range cat.Artists {
+		ix.add(artist.ID, FieldArtist, artist.Name)
+		for _, member := range artist.Members {
+			ix.add(artist.ID, FieldMember, member)
+		}
+		// Index the readable name: "los_angeles-usa" as "Los Angeles, USA"
+		for _, location := range cat.Locations[artist.ID].Locations {
+			ix.add(artist.ID, FieldLocation, catalog.FormatLocation(location))
+		}
+		ix.add(artist.ID, FieldFirstAlbum, artist.FirstAlbum)
+		ix.add(artist.ID, FieldCreationDate, strconv.Itoa(artist.CreationDate))
+	}
+
+	for term := range ix.postings {
+		ix.terms = append(ix.terms, term)
+		for _, g := range trigrams(term) {
+			ix.grams[g] = append(ix.grams[g], term)
+		}
+	}
+	sort.Strings(ix.terms)
+	return ix
+}
+
+func (ix *Index) add(artistID int, field, text string) {
+	tokens := tokenize(text)
+	if len(tokens) == 0 {
+		return
+	}
+
+	doc := len(ix.docs)
+	ix.docs = append(ix.docs, document{artistID: artistID, field: field, text: text, tokens: tokens})
+	for pos, t := range tokens {
+		ix.postings[t.term] = append(ix.postings[t.term], posting{doc: doc, pos: pos})
+	}
+}
+
+// hit is the best match found for one query word within an artist
+type hit struct {
+	score  float64
+	doc    int
+	pos    int
+	prefix int // runes matched when the query word is a prefix, else 0
+	exact  bool
+}
+
+func (h hit) better(o hit) bool {
+	if h.score != o.score {
+		return h.score > o.score
+	}
+	if h.doc != o.doc {
+		return h.doc < o.doc
+	}
+	return h.pos < o.pos
+}
+
+// Search returns up to limit artists matching every word of the query,
+// best first. Words match exactly, as a prefix, or with a few typos.
+func (ix *Index) Search(query string, limit int) []Result {
+	words := tokenize(query)
+	if len(words) == 0 || limit <= 0 {
+		return nil
+	}
+
+	// 1. Best hit of each query word, per artist
+	hits := make(map[int][]hit)
+	for i, word := range words {
+		for term, m := range ix.expand(word.term) {
+			for _, p := range ix.postings[term] {
+				doc := ix.docs[p.doc]
+				h := hit{score: m.score * fieldWeights[doc.field], doc: p.doc, pos: p.pos, prefix: m.prefix, exact: m.exact}
+
+				artistHits := hits[doc.artistID]
+				if artistHits == nil {
+					artistHits = make([]hit, len(words))
+					hits[doc.artistID] = artistHits
+				}
+				if artistHits[i].score == 0 || h.better(artistHits[i]) {
+					artistHits[i] = h
+				}
+			}
+		}
+	}
+
+	// 2. Keep artists matching every word and score them
+	var results []Result
+	for artistID, artistHits := range hits {
+		if result, ok := ix.result(artistID, artistHits); ok {
+			results = append(results, result)
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].ArtistID < results[j].ArtistID
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// termMatch is how an indexed term matches a query word
+type termMatch struct {
+	score  float64
+	prefix int
+	exact  bool
+}
+
+// expand finds the indexed terms matching a query word exactly, as a
+// prefix or within its typo tolerance
+func (ix *Index) expand(word string) map[string]termMatch {
+	matches := make(map[string]termMatch)
+	wordLen := utf8.RuneCountInString(word)
+
+	// Prefixes, including the word itself
+	for i := sort.SearchStrings(ix.terms, word); i < len(ix.terms) && strings.HasPrefix(ix.terms[i], word); i++ {
+		term := ix.terms[i]
+		if term == word {
+			matches[term] = termMatch{score: scoreExact, exact: true}
+			continue
+		}
+		typed := float64(wordLen) / float64(utf8.RuneCountInString(term))
+		matches[term] = termMatch{score: scorePrefix + 0.3*typed, prefix: wordLen}
+	}
+
+	// Typos. Each edit changes at most three trigrams, so a term within
+	// reach shares all but 3*edits of the word's trigrams.
+	edits := maxEdits(word)
+	if edits == 0 || hasDigit(word) {
+		return matches
+	}
+	grams := trigrams(word)
+	shared := make(map[string]int)
+	for _, g := range grams {
+		for _, term := range ix.grams[g] {
+			shared[term]++
+		}
+	}
+	for term, n := range shared {
+		if _, ok := matches[term]; ok || n < len(grams)-3*edits || hasDigit(term) {
+			continue
+		}
+		if d := levenshtein(word, term, edits); d <= edits {
+			matches[term] = termMatch{score: scoreFuzzy - scorePerEdit*float64(d-1)}
+		}
+	}
+	return matches
+}
+
+// result scores an artist from the hits of every query word. It is shown
+// with the document holding most of the hits.
+func (ix *Index) result(artistID int, hits []hit) (Result, bool) {
+	total := 0.0
+	perDoc := make(map[int]int)
+	for _, h := range hits {
+		if h.score == 0 {
+			return Result{}, false
+		}
+		total += h.score
+		perDoc[h.doc]++
+	}
+	score := total / float64(len(hits))
+
+	best := hits[0].doc
+	for doc, n := range perDoc {
+		if n > perDoc[best] || (n == perDoc[best] && doc < best) {
+			best = doc
+		}
+	}
+	doc := ix.docs[best]
+
+	// The query is the whole field, word for word
+	if perDoc[best] == len(hits) && len(doc.tokens) == len(hits) && allExact(hits) {
+		score += bonusWholeField
+	}
+	if hits[0].doc == best && hits[0].pos == 0 {
+		score += bonusFirstWord
+	}
+
+	result := Result{
+		ArtistID:   artistID,
+		Score:      math.Round(score*1000) / 1000,
+		Field:      doc.field,
+		Text:       doc.text,
+		Highlights: []Span{},
+	}
+	for _, h := range hits {
+		if h.doc != best {
+			continue
+		}
+		t := doc.tokens[h.pos]
+		span := Span{Start: t.start, End: t.end}
+		if h.prefix > 0 {
+			span.End = prefixEnd(doc.text, t.start, h.prefix)
+		}
+		result.Highlights = append(result.Highlights, span)
+	}
+	result.Highlights = mergeSpans(result.Highlights)
+	return result, true
+}
+
+func allExact(hits []hit) bool {
+	for _, h := range hits {
+		if !h.exact {
+			return false
+		}
+	}
+	return true
+}
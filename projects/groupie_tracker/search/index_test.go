@@ -0,0 +1,205 @@
+package search
+
+import (
+	"flag"
+	"fmt"
+	"testing"
+
+	"groupie_tracker/catalog"
+	"groupie_tracker/models"
+)
+
+var snapshot = flag.String("snapshot", "", "catalog snapshot to benchmark against instead of a generated catalog")
+
+func testIndex() *Index {
+	return Build(catalog.New(
+		[]models.Artist{
+			{ID: 1, Name: "Queen", Members: []string{"Freddie Mercury", "Brian May"}, CreationDate: 1970, FirstAlbum: "14-12-1973"},
+			{ID: 2, Name: "Pink Floyd", Members: []string{"Roger Waters", "David Gilmour"}, CreationDate: 1965, FirstAlbum: "05-08-1967"},
+			{ID: 3, Name: "Beyoncé", Members: []string{"Beyoncé Knowles"}, CreationDate: 1997, FirstAlbum: "24-06-2003"},
+			{ID: 4, Name: "Queen Latifah", Members: []string{"Dana Owens"}, CreationDate: 1988, FirstAlbum: "28-11-1989"},
+		},
+		[]models.Locations{
+			{ID: 1, Locations: []string{"london-uk", "los_angeles-usa"}},
+			{ID: 2, Locations: []string{"paris-france"}},
+			{ID: 3, Locations: []string{"houston-usa"}},
+			{ID: 4, Locations: []string{"newark-usa"}},
+		},
+		nil,
+		nil,
+	))
+}
+
+func TestSearchTypos(t *testing.T) {
+	ix := testIndex()
+
+	tests := []struct {
+		query string
+		id    int
+		field string
+	}{
+		{"queen", 1, FieldArtist},
+		{"Pink Floid", 2, FieldArtist},
+		{"los angelos", 1, FieldLocation},
+		{"gilmor", 2, FieldMember},
+		{"beyonce", 3, FieldArtist},
+		{"PARIS", 2, FieldLocation},
+		{"1965", 2, FieldCreationDate},
+		{"merc", 1, FieldMember},
+	}
+
+	for _, tt := range tests {
+		results := ix.Search(tt.query, 10)
+		if len(results) == 0 {
+			t.Errorf("%q: expected a result", tt.query)
+			continue
+		}
+		if results[0].ArtistID != tt.id || results[0].Field != tt.field {
+			t.Errorf("%q: expected artist %d by %s first, got %+v", tt.query, tt.id, tt.field, results[0])
+		}
+	}
+}
+
+func TestSearchNoMatch(t *testing.T) {
+	ix := testIndex()
+
+	for _, query := range []string{"", "  ", "zeppelin", "1966", "pink zeppelin"} {
+		if results := ix.Search(query, 10); len(results) != 0 {
+			t.Errorf("%q: expected no results, got %+v", query, results)
+		}
+	}
+}
+
+func TestSearchRanking(t *testing.T) {
+	ix := testIndex()
+
+	// The exact name beats a longer name that starts the same way
+	results := ix.Search("queen", 10)
+	if len(results) != 2 || results[0].ArtistID != 1 || results[1].ArtistID != 4 {
+		t.Fatalf("Expected Queen then Queen Latifah, got %+v", results)
+	}
+	if results[0].Score <= results[1].Score {
+		t.Errorf("Expected the exact match to score higher, got %v and %v", results[0].Score, results[1].Score)
+	}
+
+	// Words may match different fields of the same artist
+	results = ix.Search("queen london", 10)
+	if len(results) != 1 || results[0].ArtistID != 1 {
+		t.Errorf("Expected only Queen, got %+v", results)
+	}
+
+	if results := ix.Search("queen", 1); len(results) != 1 {
+		t.Errorf("Expected the limit to apply, got %d results", len(results))
+	}
+}
+
+func TestSearchHighlights(t *testing.T) {
+	ix := testIndex()
+
+	tests := []struct {
+		query string
+		text  string
+		want  []Span
+	}{
+		{"pink floid", "Pink Floyd", []Span{{0, 4}, {5, 10}}},
+		{"floyd", "Pink Floyd", []Span{{5, 10}}},
+		{"gilm", "David Gilmour", []Span{{6, 10}}},
+		{"beyon", "Beyoncé", []Span{{0, 5}}},
+		{"beyonce", "Beyoncé", []Span{{0, 8}}},
+	}
+
+	for _, tt := range tests {
+		results := ix.Search(tt.query, 1)
+		if len(results) == 0 || results[0].Text != tt.text {
+			t.Errorf("%q: expected a match on %q, got %+v", tt.query, tt.text, results)
+			continue
+		}
+		if fmt.Sprint(results[0].Highlights) != fmt.Sprint(tt.want) {
+			t.Errorf("%q: expected highlights %v, got %v", tt.query, tt.want, results[0].Highlights)
+		}
+	}
+}
+
+func TestSegments(t *testing.T) {
+	got := Segments("Pink Floyd", []Span{{5, 10}, {0, 2}, {1, 4}})
+	want := []Segment{{"Pink", true}, {" ", false}, {"Floyd", true}}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		max  int
+		want int
+	}{
+		{"floid", "floyd", 2, 1},
+		{"angelos", "angeles", 2, 1},
+		{"kitten", "sitting", 3, 3},
+		{"kitten", "sitting", 2, 3}, // cut off at max+1
+		{"queen", "queen", 1, 0},
+		{"", "abc", 5, 3},
+	}
+
+	for _, tt := range tests {
+		if got := levenshtein(tt.a, tt.b, tt.max); got != tt.want {
+			t.Errorf("levenshtein(%q, %q, %d) = %d, expected %d", tt.a, tt.b, tt.max, got, tt.want)
+		}
+	}
+}
+
+// benchmarkCatalog is the snapshot given with -snapshot, or a generated
+// catalog the size of the upstream one
+func benchmarkCatalog(b *testing.B) *catalog.Catalog {
+	if *snapshot != "" {
+		cat, err := catalog.LoadSnapshot(*snapshot)
+		if err != nil {
+			b.Fatal(err)
+		}
+		return cat
+	}
+
+	words := []string{"queen", "pink", "floyd", "scorpions", "linkin", "park", "coldplay", "gorillaz", "eagles", "metallica"}
+	places := []string{"london-uk", "los_angeles-usa", "paris-france", "osaka-japan", "sao_paulo-brazil", "berlin-germany", "new_york-usa", "sydney-australia"}
+
+	var artists []models.Artist
+	var locations []models.Locations
+	for id := 1; id <= 52; id++ {
+		artist := models.Artist{
+			ID:           id,
+			Name:         fmt.Sprintf("%s %s", words[id%len(words)], words[(id*7)%len(words)]),
+			CreationDate: 1950 + id,
+			FirstAlbum:   fmt.Sprintf("%02d-%02d-%d", id%28+1, id%12+1, 1960+id),
+		}
+		for m := 0; m < 5; m++ {
+			artist.Members = append(artist.Members, fmt.Sprintf("Member%d %s", id*5+m, words[(id+m)%len(words)]))
+		}
+		artists = append(artists, artist)
+
+		var locs []string
+		for l := 0; l < 8; l++ {
+			locs = append(locs, places[(id+l)%len(places)])
+		}
+		locations = append(locations, models.Locations{ID: id, Locations: locs})
+	}
+	return catalog.New(artists, locations, nil, nil)
+}
+
+func BenchmarkBuild(b *testing.B) {
+	cat := benchmarkCatalog(b)
+	for b.Loop() {
+		Build(cat)
+	}
+}
+
+func BenchmarkSearch(b *testing.B) {
+	ix := Build(benchmarkCatalog(b))
+	queries := []string{"queen", "Pink Floid", "los angelos", "metalica", "1970", "sao"}
+
+	for b.Loop() {
+		for _, q := range queries {
+			ix.Search(q, 20)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// token is one word of a document, with its byte offsets in the
+// original text so matches can be highlighted
+type token struct {
+	term       string // normalized form
+	start, end int
+}
+
+// folds maps accented Latin letters to their plain form, so "Beyonce"
+// finds "Beyoncé". Each entry is one rune to one rune, which keeps rune
+// counts of the normalized and original text in step.
+var folds = map[rune]rune{
+	'à': 'a', 'á': 'a', 'â': 'a', 'ã': 'a', 'ä': 'a', 'å': 'a', 'ā': 'a',
+	'ç': 'c', 'ć': 'c', 'č': 'c',
+	'è': 'e', 'é': 'e', 'ê': 'e', 'ë': 'e', 'ē': 'e', 'ę': 'e', 'ě': 'e',
+	'ì': 'i', 'í': 'i', 'î': 'i', 'ï': 'i', 'ī': 'i',
+	'ñ': 'n', 'ń': 'n', 'ň': 'n',
+	'ò': 'o', 'ó': 'o', 'ô': 'o', 'õ': 'o', 'ö': 'o', 'ø': 'o', 'ō': 'o', 'ő': 'o',
+	'ù': 'u', 'ú': 'u', 'û': 'u', 'ü': 'u', 'ū': 'u', 'ů': 'u', 'ű': 'u',
+	'ý': 'y', 'ÿ': 'y',
+	'ś': 's', 'š': 's', 'ş': 's',
+	'ź': 'z', 'ż': 'z', 'ž': 'z',
+	'ł': 'l', 'ř': 'r', 'ť': 't', 'ď': 'd', 'ğ': 'g',
+}
+
+// fold lower-cases r and strips its accent
+func fold(r rune) rune {
+	r = unicode.ToLower(r)
+	if f, ok := folds[r]; ok {
+		return f
+	}
+	return r
+}
+
+// isWordRune reports whether r is part of a word. Everything else,
+// including the '-' and '_' of location slugs, separates words.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '\''
+}
+
+// tokenize splits text into normalized words
+func tokenize(text string) []token {
+	var tokens []token
+	var term strings.Builder
+	start := -1
+
+	for i, r := range text {
+		if isWordRune(r) {
+			if start < 0 {
+				start = i
+			}
+			if r != '\'' {
+				// "O'Shea" is indexed as "oshea"
+				term.WriteRune(fold(r))
+			}
+			continue
+		}
+		if start >= 0 {
+			tokens = appendToken(tokens, &term, start, i)
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = appendToken(tokens, &term, start, len(text))
+	}
+	return tokens
+}
+
+func appendToken(tokens []token, term *strings.Builder, start, end int) []token {
+	if term.Len() > 0 {
+		tokens = append(tokens, token{term: term.String(), start: start, end: end})
+	}
+	term.Reset()
+	return tokens
+}
+
+// prefixEnd returns the byte offset in text after the first n runes
+// of the word starting at start, skipping apostrophes as tokenize does
+func prefixEnd(text string, start, n int) int {
+	i := start
+	for n > 0 && i < len(text) {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if r != '\'' {
+			n--
+		}
+		i += size
+	}
+	return i
+}
+
+// hasDigit reports whether a term contains a digit. Years and dates are
+// matched exactly: 1970 is not a typo of 1971.
+func hasDigit(term string) bool {
+	return strings.IndexFunc(term, unicode.IsDigit) >= 0
+}
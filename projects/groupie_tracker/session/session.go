@@ -0,0 +1,113 @@
+package session
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// CookieName is the cookie that carries the signed session ID
+const CookieName = "groupie_session"
+
+// maxAge keeps a session alive for a year of inactivity
+const maxAge = 365 * 24 * time.Hour
+
+// Manager issues and verifies session cookies signed with HMAC-SHA256.
+// Sessions are anonymous: the ID only ties requests together.
+type Manager struct {
+	secret []byte
+}
+
+// NewManager creates a manager. Cookies signed with a different secret
+// are rejected, so the secret must be stable across restarts for
+// sessions to survive them.
+func NewManager(secret []byte) *Manager {
+	return &Manager{secret: secret}
+}
+
+// RandomSecret returns a fresh 32-byte secret
+func RandomSecret() []byte {
+	secret := make([]byte, 32)
+	rand.Read(secret)
+	return secret
+}
+
+func (m *Manager) sign(value string) string {
+	mac := hmac.New(sha256.New, m.secret)
+	mac.Write([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// verify returns the session ID held by a cookie value, if the signature matches
+func (m *Manager) verify(cookie string) (string, bool) {
+	id, sig, ok := strings.Cut(cookie, ".")
+	if !ok || id == "" {
+		return "", false
+	}
+	if !hmac.Equal([]byte(sig), []byte(m.sign(id))) {
+		return "", false
+	}
+	return id, true
+}
+
+// ID returns the session ID of the request without creating one
+func (m *Manager) ID(r *http.Request) (string, bool) {
+	cookie, err := r.Cookie(CookieName)
+	if err != nil {
+		return "", false
+	}
+	return m.verify(cookie.Value)
+}
+
+// Ensure returns the session ID of the request, starting a new session
+// and setting its cookie if there is none or it has been tampered with
+func (m *Manager) Ensure(w http.ResponseWriter, r *http.Request) string {
+	if id, ok := m.ID(r); ok {
+		return id
+	}
+
+	raw := make([]byte, 18)
+	rand.Read(raw)
+	id := base64.RawURLEncoding.EncodeToString(raw)
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     CookieName,
+		Value:    id + "." + m.sign(id),
+		Path:     "/",
+		MaxAge:   int(maxAge.Seconds()),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return id
+}
+
+// CSRFToken returns the token forms must echo back for the session.
+// It is derived from the session ID, so nothing has to be stored.
+func (m *Manager) CSRFToken(id string) string {
+	return m.sign("csrf:" + id)
+}
+
+// ValidCSRF reports whether token belongs to the session
+func (m *Manager) ValidCSRF(id, token string) bool {
+	return token != "" && hmac.Equal([]byte(token), []byte(m.CSRFToken(id)))
+}
+
+// FeedToken returns a token that identifies the session in URLs, for
+// clients such as calendar apps that cannot send the cookie
+func (m *Manager) FeedToken(id string) string {
+	return id + "." + m.sign("feed:"+id)
+}
+
+// FeedSession returns the session ID of a token made by FeedToken
+func (m *Manager) FeedSession(token string) (string, bool) {
+	id, sig, ok := strings.Cut(token, ".")
+	if !ok || id == "" || !hmac.Equal([]byte(sig), []byte(m.sign("feed:"+id))) {
+		return "", false
+	}
+	return id, true
+}
@@ -0,0 +1,53 @@
+package session
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEnsureReusesSignedCookie(t *testing.T) {
+	m := NewManager([]byte("test-secret"))
+
+	rec := httptest.NewRecorder()
+	id := m.Ensure(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("Expected one session cookie, got %d", len(cookies))
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(cookies[0])
+	if got, ok := m.ID(req); !ok || got != id {
+		t.Errorf("Expected session %q from cookie, got %q (ok=%v)", id, got, ok)
+	}
+}
+
+func TestTamperedCookieRejected(t *testing.T) {
+	m := NewManager([]byte("test-secret"))
+	other := NewManager([]byte("other-secret"))
+
+	rec := httptest.NewRecorder()
+	other.Ensure(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(rec.Result().Cookies()[0])
+	if _, ok := m.ID(req); ok {
+		t.Error("Expected a cookie signed with another secret to be rejected")
+	}
+}
+
+func TestCSRFToken(t *testing.T) {
+	m := NewManager([]byte("test-secret"))
+
+	if !m.ValidCSRF("abc", m.CSRFToken("abc")) {
+		t.Error("Expected the session's own token to be valid")
+	}
+	if m.ValidCSRF("abc", m.CSRFToken("xyz")) {
+		t.Error("Expected another session's token to be rejected")
+	}
+	if m.ValidCSRF("abc", "") {
+		t.Error("Expected an empty token to be rejected")
+	}
+}
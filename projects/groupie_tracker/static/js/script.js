@@ -0,0 +1,184 @@
+// Search-as-you-type: inputs marked with data-suggest get a list of
+// suggestions from the server, navigable with the keyboard.
+(function () {
+    'use strict';
+
+    var DEBOUNCE_MS = 200;
+
+    function setupSuggest(input, index) {
+        var form = input.form;
+        var list = document.createElement('ul');
+        list.id = 'suggestions-' + index;
+        list.className = 'suggestions';
+        list.setAttribute('role', 'listbox');
+        list.setAttribute('aria-label', input.getAttribute('data-suggest-label') || '');
+        list.hidden = true;
+        form.classList.add('has-suggestions');
+        form.appendChild(list);
+
+        input.setAttribute('role', 'combobox');
+        input.setAttribute('aria-autocomplete', 'list');
+        input.setAttribute('aria-controls', list.id);
+        input.setAttribute('aria-expanded', 'false');
+        input.setAttribute('autocomplete', 'off');
+
+        var timer = null;
+        var controller = null;
+        var items = [];
+        var active = -1;
+
+        function close() {
+            list.hidden = true;
+            input.setAttribute('aria-expanded', 'false');
+            input.removeAttribute('aria-activedescendant');
+            active = -1;
+        }
+
+        function highlight(i) {
+            if (active >= 0 && items[active]) {
+                items[active].setAttribute('aria-selected', 'false');
+            }
+            active = i;
+            if (active >= 0) {
+                items[active].setAttribute('aria-selected', 'true');
+                items[active].scrollIntoView({ block: 'nearest' });
+                input.setAttribute('aria-activedescendant', items[active].id);
+            } else {
+                input.removeAttribute('aria-activedescendant');
+            }
+        }
+
+        function render(suggestions) {
+            list.textContent = '';
+            items = [];
+            active = -1;
+            if (suggestions.length === 0) {
+                close();
+                return;
+            }
+
+            suggestions.forEach(function (s, i) {
+                var item = document.createElement('li');
+                item.id = list.id + '-' + i;
+                item.setAttribute('role', 'option');
+                item.setAttribute('aria-selected', 'false');
+                item.dataset.url = s.url;
+
+                var text = document.createElement('span');
+                text.className = 'suggestion-text';
+                text.textContent = s.text;
+                var label = document.createElement('span');
+                label.className = 'suggestion-type';
+                label.textContent = s.label;
+                item.appendChild(text);
+                item.appendChild(label);
+
+                // mousedown fires before the input loses focus
+                item.addEventListener('mousedown', function (e) {
+                    e.preventDefault();
+                    window.location.href = s.url;
+                });
+                list.appendChild(item);
+                items.push(item);
+            });
+
+            list.hidden = false;
+            input.setAttribute('aria-expanded', 'true');
+        }
+
+        function fetchSuggestions() {
+            var q = input.value.trim();
+            if (controller) {
+                controller.abort();
+            }
+            if (q === '') {
+                render([]);
+                return;
+            }
+
+            controller = new AbortController();
+            fetch(input.getAttribute('data-suggest') + '?q=' + encodeURIComponent(q), {
+                headers: { 'Accept': 'application/json' },
+                signal: controller.signal
+            })
+                .then(function (resp) {
+                    return resp.ok ? resp.json() : { suggestions: [] };
+                })
+                .then(function (data) {
+                    // Ignore answers for text the user has since changed
+                    if (data.query === undefined || data.query.trim() === input.value.trim()) {
+                        render(data.suggestions || []);
+                    }
+                })
+                .catch(function (err) {
+                    if (err.name !== 'AbortError') {
+                        close();
+                    }
+                });
+        }
+
+        input.addEventListener('input', function () {
+            clearTimeout(timer);
+            timer = setTimeout(fetchSuggestions, DEBOUNCE_MS);
+        });
+
+        input.addEventListener('keydown', function (e) {
+            if (list.hidden || items.length === 0) {
+                if (e.key === 'ArrowDown' && input.value.trim() !== '') {
+                    fetchSuggestions();
+                    e.preventDefault();
+                }
+                return;
+            }
+
+            switch (e.key) {
+            case 'ArrowDown':
+                highlight((active + 1) % items.length);
+                e.preventDefault();
+                break;
+            case 'ArrowUp':
+                highlight(active <= 0 ? items.length - 1 : active - 1);
+                e.preventDefault();
+                break;
+            case 'Enter':
+                // Without a highlighted suggestion the form submits as usual
+                if (active >= 0) {
+                    window.location.href = items[active].dataset.url;
+                    e.preventDefault();
+                }
+                break;
+            case 'Escape':
+                close();
+                e.preventDefault();
+                break;
+            }
+        });
+
+        input.addEventListener('blur', close);
+    }
+
+    document.querySelectorAll('input[data-suggest]').forEach(setupSuggest);
+})();
+
+// Refresh notifications: pages with an update banner show it when the
+// server reports that the catalog changed.
+(function () {
+    'use strict';
+
+    var banner = document.querySelector('.update-banner');
+    if (!banner || !window.EventSource) {
+        return;
+    }
+
+    var source = new EventSource('/events');
+    source.addEventListener('change', function () {
+        banner.hidden = false;
+        // Nothing more to learn once the page is known to be stale
+        source.close();
+    });
+
+    banner.querySelector('a').addEventListener('click', function (e) {
+        e.preventDefault();
+        window.location.reload();
+    });
+})();